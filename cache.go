@@ -0,0 +1,255 @@
+package esql
+
+import (
+	"container/list"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache 查询结果缓存
+type Cache interface {
+	// Get 获取缓存，不存在或已过期时返回false
+	Get(key string) (any, bool)
+	// Set 写入缓存，tables 为结果所依赖的表，任意一张表发生写入时缓存失效
+	Set(key string, value any, ttl time.Duration, tables ...string)
+	// Invalidate 使依赖该表的缓存全部失效
+	Invalidate(table string)
+}
+
+// CacheKey 根据渲染后的SQL、参数以及加载的关系生成缓存键
+func CacheKey(query string, args []any, with ...string) string {
+	with = append([]string(nil), with...)
+	sort.Strings(with)
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = cacheArg(arg)
+	}
+	return fmt.Sprintf("%s|%s|%s", query, strings.Join(values, ","), strings.Join(with, ","))
+}
+
+// cacheArg 参数在缓存键中的表示，指针按指向的值、driver.Valuer 按 Value() 的结果、
+// 时间按 RFC3339Nano 格式化，相同取值的参数生成相同的键
+func cacheArg(arg any) string {
+	if arg == nil {
+		return "nil"
+	}
+	rv := reflect.ValueOf(arg)
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return "nil"
+	}
+	switch v := arg.(type) {
+	case driver.Valuer:
+		dv, err := v.Value()
+		if err != nil {
+			return fmt.Sprintf("%#v", arg)
+		}
+		return cacheArg(dv)
+	case time.Time:
+		return "time(" + v.Format(time.RFC3339Nano) + ")"
+	}
+	if rv.Kind() == reflect.Pointer {
+		return cacheArg(rv.Elem().Interface())
+	}
+	return fmt.Sprintf("%#v", arg)
+}
+
+// DeepCopy 深拷贝缓存的数据，指针、切片、map 以及接口中的值都会复制，
+// 写入与读取缓存时都需要拷贝，避免调用方修改缓存中的数据。结构体的未导出字段按值复制
+func DeepCopy[T any](v T) T {
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	deepCopy(dst, src)
+	return dst.Interface().(T)
+}
+
+func deepCopy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		deepCopy(dst.Elem(), src.Elem())
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		v := reflect.New(src.Elem().Type()).Elem()
+		deepCopy(v, src.Elem())
+		dst.Set(v)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		iter := src.MapRange()
+		for iter.Next() {
+			v := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(v, iter.Value())
+			dst.SetMapIndex(iter.Key(), v)
+		}
+	case reflect.Struct:
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				deepCopy(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// LRUCache 基于内存的LRU缓存，并发安全
+type LRUCache struct {
+	mu     sync.Mutex
+	size   int
+	ll     *list.List
+	items  map[string]*list.Element
+	tables map[string]map[string]struct{}
+}
+
+type lruEntry struct {
+	key    string
+	value  any
+	expire time.Time
+	tables []string
+}
+
+// NewLRUCache 创建LRU缓存，size 为最多缓存的条目数
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:   size,
+		ll:     list.New(),
+		items:  make(map[string]*list.Element),
+		tables: make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *LRUCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expire) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (c *LRUCache) Set(key string, value any, ttl time.Duration, tables ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+	entry := &lruEntry{
+		key:    key,
+		value:  value,
+		expire: time.Now().Add(ttl),
+		tables: tables,
+	}
+	c.items[key] = c.ll.PushFront(entry)
+	for _, table := range tables {
+		if c.tables[table] == nil {
+			c.tables[table] = make(map[string]struct{})
+		}
+		c.tables[table][key] = struct{}{}
+	}
+	for c.size > 0 && c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *LRUCache) Invalidate(table string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.tables[table] {
+		if elem, ok := c.items[key]; ok {
+			c.remove(elem)
+		}
+	}
+	delete(c.tables, table)
+}
+
+// Len 返回当前缓存的条目数
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+func (c *LRUCache) remove(elem *list.Element) {
+	entry := c.ll.Remove(elem).(*lruEntry)
+	delete(c.items, entry.key)
+	for _, table := range entry.tables {
+		delete(c.tables[table], entry.key)
+	}
+}
+
+// TxCache 事务中使用的缓存，事务内不读写缓存，避免缓存未提交的数据；
+// 事务中写入的表在 Commit 后统一失效，Rollback 时直接丢弃
+type TxCache struct {
+	cache  Cache
+	mu     sync.Mutex
+	tables []string
+}
+
+// NewTxCache 创建事务缓存，cache 为客户端的缓存，可以为nil
+func NewTxCache(cache Cache) *TxCache {
+	return &TxCache{cache: cache}
+}
+
+func (c *TxCache) Get(string) (any, bool) {
+	return nil, false
+}
+
+func (c *TxCache) Set(string, any, time.Duration, ...string) {}
+
+func (c *TxCache) Invalidate(table string) {
+	if c.cache == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tables = append(c.tables, table)
+}
+
+// Commit 事务提交后使事务中写入的表的缓存失效
+func (c *TxCache) Commit() {
+	c.mu.Lock()
+	tables := c.tables
+	c.tables = nil
+	c.mu.Unlock()
+
+	for _, table := range tables {
+		c.cache.Invalidate(table)
+	}
+}
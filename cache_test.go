@@ -0,0 +1,106 @@
+package esql
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("a", 1, time.Minute, "role")
+	c.Set("b", 2, time.Minute, "user", "role")
+	c.Set("c", 3, time.Minute, "user")
+
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Get(a) should be evicted")
+	}
+	if v, ok := c.Get("b"); !ok || v != 2 {
+		t.Errorf("Get(b) = %v, %v, want 2, true", v, ok)
+	}
+
+	c.Invalidate("role")
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("Get(b) should be invalidated by role")
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Errorf("Get(c) = %v, %v, want 3, true", v, ok)
+	}
+
+	c.Set("d", 4, -time.Second, "user")
+	if _, ok := c.Get("d"); ok {
+		t.Errorf("Get(d) should be expired")
+	}
+}
+
+func TestTxCache(t *testing.T) {
+	c := NewLRUCache(10)
+	c.Set("a", 1, time.Minute, "user")
+	tc := NewTxCache(c)
+	tc.Set("b", 2, time.Minute, "user")
+	if _, ok := tc.Get("a"); ok {
+		t.Errorf("Get(a) should not read the cache in tx")
+	}
+	tc.Invalidate("user")
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("Get(a) should be kept before commit")
+	}
+	tc.Commit()
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Get(a) should be invalidated after commit")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", c.Len())
+	}
+	NewTxCache(nil).Invalidate("user")
+}
+
+func TestDeepCopy(t *testing.T) {
+	type edge struct {
+		Name *string
+	}
+	type data struct {
+		Id    int
+		Raw   []byte
+		Tags  map[string][]int
+		Edge  *edge
+		Edges []*edge
+		Poly  any
+		at    time.Time
+	}
+	name := "a"
+	src := &data{
+		Id:    1,
+		Raw:   []byte("{}"),
+		Tags:  map[string][]int{"a": {1}},
+		Edge:  &edge{Name: &name},
+		Edges: []*edge{{Name: &name}},
+		Poly:  &edge{Name: &name},
+		at:    time.Unix(1, 0),
+	}
+	dst := DeepCopy(src)
+	dst.Raw[0] = '['
+	dst.Tags["a"][0] = 2
+	*dst.Edge.Name = "b"
+	dst.Edges[0].Name = nil
+	dst.Poly.(*edge).Name = nil
+	if string(src.Raw) != "{}" || src.Tags["a"][0] != 1 || name != "a" || src.Edges[0].Name == nil || src.Poly.(*edge).Name == nil {
+		t.Errorf("DeepCopy() shares data with the source: %+v", src)
+	}
+	if !dst.at.Equal(src.at) || dst.Id != 1 {
+		t.Errorf("DeepCopy() = %+v, want %+v", dst, src)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	a, b := "kenka", "kenka"
+	at, bt := time.Unix(1, 0), time.Unix(1, 0)
+	ka := CacheKey("SELECT", []any{&a, &at, sql.NullString{String: a, Valid: true}, (*int)(nil)})
+	kb := CacheKey("SELECT", []any{&b, &bt, &sql.NullString{String: b, Valid: true}, nil})
+	if ka != kb {
+		t.Errorf("CacheKey() = %s and %s, want equal keys", ka, kb)
+	}
+	if CacheKey("SELECT", []any{"a"}) == CacheKey("SELECT", []any{"b"}) {
+		t.Error("CacheKey() of different args should differ")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-kenka/esql/dsl/ast"
 	"github.com/go-kenka/esql/gen"
 	"github.com/go-kenka/esql/uitls"
+	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -25,11 +26,22 @@ var genCmd = &cobra.Command{
 		// 代码生成路径
 		targetPath, _ := cmd.Flags().GetString("target")
 		// 读取schema定义
-		tbs := ast.ReadDir(schemaPath)
+		tbs, err := ast.ReadDir(schemaPath)
+		if err != nil {
+			panic(err)
+		}
+		// 检查schema定义，存在错误时不生成代码
+		diags := ast.Validate(tbs)
+		for _, d := range diags {
+			fmt.Fprintln(os.Stderr, d)
+		}
+		if diags.HasErrors() {
+			os.Exit(1)
+		}
 		// 获取当前项目path路径
 		pkg := uitls.PkgPath(targetPath)
 		// 开始生成代码
-		err := gen.GenClient(targetPath, pkg, tbs)
+		err = gen.GenClient(targetPath, pkg, tbs)
 		if err != nil {
 			panic(err)
 		}
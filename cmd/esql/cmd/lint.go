@@ -0,0 +1,40 @@
+/*
+Copyright © 2023 go-kenka <1107015496@qq.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-kenka/esql/dsl/ast"
+
+	"github.com/spf13/cobra"
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "检查DSL定义",
+	Long:  `检查DSL定义，输出 file:line:col 格式的错误和警告，存在错误时以非0状态码退出`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		tbs, err := ast.ReadDir(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		diags := ast.Validate(tbs)
+		for _, d := range diags {
+			fmt.Fprintln(os.Stderr, d)
+		}
+		if diags.HasErrors() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+}
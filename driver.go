@@ -3,29 +3,113 @@ package esql
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+
 	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
 )
 
+// Driver 生成代码使用的数据库接口，只包含带 context 的方法，
+// *sqlx.DB、*sqlx.Tx 可以直接使用，*sql.DB、*sql.Conn 通过 FromDB、FromConn 适配
 type Driver interface {
 	DriverName() string
 	Rebind(query string) string
-	BindNamed(query string, arg interface{}) (string, []interface{}, error)
-	NamedQuery(query string, arg interface{}) (*sqlx.Rows, error)
-	NamedExec(query string, arg interface{}) (sql.Result, error)
-	Select(dest interface{}, query string, args ...interface{}) error
-	Queryx(query string, args ...interface{}) (*sqlx.Rows, error)
-	QueryRowx(query string, args ...interface{}) *sqlx.Row
-	Get(dest interface{}, query string, args ...interface{}) error
-	MustExec(query string, args ...interface{}) sql.Result
-	Preparex(query string) (*sqlx.Stmt, error)
-	PrepareNamed(query string) (*sqlx.NamedStmt, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error)
+	QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row
 	PreparexContext(ctx context.Context, query string) (*sqlx.Stmt, error)
-	PrepareNamedContext(ctx context.Context, query string) (*sqlx.NamedStmt, error)
-	MustExecContext(ctx context.Context, query string, args ...interface{}) sql.Result
-	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
-	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
-	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
-	QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row
-	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
-	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+var (
+	_ Driver = (*sqlx.DB)(nil)
+	_ Driver = (*sqlx.Tx)(nil)
+)
+
+// FromDB 将 *sql.DB 适配为 Driver，driverName 为数据库方言，例如 dialect.MySQL
+func FromDB(db *sql.DB, driverName string) Driver {
+	return sqlx.NewDb(db, driverName)
+}
+
+// FromConn 将 *sql.Conn 适配为 Driver，所有语句都在同一个连接上执行
+func FromConn(conn *sql.Conn, driverName string) Driver {
+	return &connDriver{
+		Conn: &sqlx.Conn{
+			Conn:   conn,
+			Mapper: reflectx.NewMapperFunc("db", strings.ToLower),
+		},
+		driverName: driverName,
+	}
+}
+
+type connDriver struct {
+	*sqlx.Conn
+	driverName string
+}
+
+func (c *connDriver) DriverName() string {
+	return c.driverName
+}
+
+func (c *connDriver) Rebind(query string) string {
+	return sqlx.Rebind(sqlx.BindType(c.driverName), query)
+}
+
+// GetContext 查询单行数据，dest 为结构体时按 db 标签映射，否则直接扫描
+func GetContext(ctx context.Context, d Driver, dest any, query string, args ...any) error {
+	return sqlx.GetContext(ctx, queryer{d}, dest, query, args...)
+}
+
+// SelectContext 查询多行数据到 dest 切片中
+func SelectContext(ctx context.Context, d Driver, dest any, query string, args ...any) error {
+	return sqlx.SelectContext(ctx, queryer{d}, dest, query, args...)
+}
+
+// queryer 将 Driver 适配为 sqlx.QueryerContext
+type queryer struct {
+	Driver
+}
+
+func (q queryer) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	rows, err := q.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows.Rows, nil
+}
+
+type sensitiveKey struct{}
+
+// WithSensitive 标记语句中敏感字段的参数，日志输出时通过 Redact 隐藏
+func WithSensitive(ctx context.Context, values ...any) context.Context {
+	if len(values) == 0 {
+		return ctx
+	}
+	vs := make([]driver.Value, 0, len(values))
+	for _, v := range values {
+		if dv, err := driver.DefaultParameterConverter.ConvertValue(v); err == nil {
+			vs = append(vs, dv)
+		}
+	}
+	return context.WithValue(ctx, sensitiveKey{}, vs)
+}
+
+// Redact 返回用于日志输出的参数，WithSensitive 标记的参数替换为 ***
+func Redact(ctx context.Context, args []any) []any {
+	vs, _ := ctx.Value(sensitiveKey{}).([]driver.Value)
+	if len(vs) == 0 {
+		return args
+	}
+	redacted := make([]any, len(args))
+	for i, arg := range args {
+		redacted[i] = arg
+		for _, v := range vs {
+			if reflect.DeepEqual(arg, v) {
+				redacted[i] = "***"
+				break
+			}
+		}
+	}
+	return redacted
 }
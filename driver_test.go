@@ -0,0 +1,23 @@
+package esql
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	args := []any{"bob", "secret", int64(1)}
+	if got := Redact(context.Background(), args); got[1] != "secret" {
+		t.Errorf("Redact without WithSensitive = %v", got)
+	}
+
+	password := "secret"
+	ctx := WithSensitive(context.Background(), &password)
+	got := Redact(ctx, args)
+	if got[0] != "bob" || got[1] != "***" || got[2] != int64(1) {
+		t.Errorf("Redact = %v", got)
+	}
+	if args[1] != "secret" {
+		t.Errorf("Redact modified args: %v", args)
+	}
+}
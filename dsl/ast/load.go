@@ -0,0 +1,156 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/go-kenka/esql/dsl"
+)
+
+// loader 加载schema包的临时程序，导入schema包触发其中的 dsl.Table 注册，
+// 再将注册的表以JSON格式写入指定文件
+var loader = template.Must(template.New("loader").Parse(`// Code generated by esql, DO NOT EDIT.
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/go-kenka/esql/dsl"
+{{- range .}}
+	_ "{{.}}"
+{{- end}}
+)
+
+func main() {
+	out, err := os.Create(os.Args[1])
+	if err != nil {
+		panic(err)
+	}
+	defer out.Close()
+
+	if err := json.NewEncoder(out).Encode(dsl.Tables()); err != nil {
+		panic(err)
+	}
+}
+`))
+
+// pkgInfo schema目录对应的包信息
+type pkgInfo struct {
+	Name       string
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+	Module     *struct {
+		Dir string
+	}
+}
+
+// load 编译并执行schema目录及其子目录中的包，收集其中通过 dsl.Table 定义的表，
+// 常量、辅助函数、循环等任意合法的Go代码都可以用来构建表定义。
+// 返回的表按包的导入路径排序，同一个包中按定义的先后顺序排列。
+func load(path string) ([]*dsl.TableExpr, error) {
+	pkgs, err := listPackages(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// 临时程序需要放在schema所在的模块中，才能使用模块的依赖
+	dir, err := os.MkdirTemp(pkgs[0].Module.Dir, "_esql_load")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	var imports []string
+	for _, pkg := range pkgs {
+		imports = append(imports, pkg.ImportPath)
+	}
+	var src bytes.Buffer
+	if err := loader.Execute(&src, imports); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+
+	result := filepath.Join(dir, "tables.json")
+	cmd := exec.Command("go", "run", ".", result)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("esql: load schema %s: %w\n%s", path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	data, err := os.ReadFile(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []*dsl.TableExpr
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// 保留数字默认值的原始写法，避免被解析为float64
+	dec.UseNumber()
+	if err := dec.Decode(&tables); err != nil {
+		return nil, err
+	}
+
+	// 包的初始化顺序由依赖关系决定，这里按定义所在的目录重新排序
+	order := make(map[string]int)
+	for i, pkg := range pkgs {
+		order[pkg.Dir] = i
+	}
+	index := func(t *dsl.TableExpr) int {
+		if i, ok := order[filepath.Dir(t.Pos.File)]; ok {
+			return i
+		}
+		return len(pkgs)
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		return index(tables[i]) < index(tables[j])
+	})
+	return tables, nil
+}
+
+// listPackages 通过 go list 获取目录及其子目录中的包，
+// 非Go文件、_test.go、main 包以及没有Go文件的目录会被忽略
+func listPackages(path string) ([]*pkgInfo, error) {
+	cmd := exec.Command("go", "list", "-json", "./...")
+	cmd.Dir = path
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("esql: list package %s: %w\n%s", path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var pkgs []*pkgInfo
+	dec := json.NewDecoder(&stdout)
+	for dec.More() {
+		var pkg pkgInfo
+		if err := dec.Decode(&pkg); err != nil {
+			return nil, err
+		}
+		// main 包无法被导入，只包含测试文件的目录没有可加载的定义
+		if pkg.Name == "main" || len(pkg.GoFiles) == 0 {
+			continue
+		}
+		if pkg.Module == nil {
+			return nil, fmt.Errorf("esql: schema %s is not inside a go module", path)
+		}
+		pkgs = append(pkgs, &pkg)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("esql: no go package found in schema %s", path)
+	}
+	return pkgs, nil
+}
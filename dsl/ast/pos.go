@@ -0,0 +1,68 @@
+package ast
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-kenka/esql/dsl"
+)
+
+// positions 补全定义位置的列号，并将文件路径转换为相对当前目录的路径
+type positions struct {
+	wd    string
+	fset  *token.FileSet
+	files map[string]*ast.File
+}
+
+func newPositions() *positions {
+	wd, _ := os.Getwd()
+	return &positions{
+		wd:    wd,
+		fset:  token.NewFileSet(),
+		files: make(map[string]*ast.File),
+	}
+}
+
+// resolve 在定义所在的行中查找名为 name 的函数调用，以其所在的列作为列号
+func (p *positions) resolve(pos dsl.Pos, name string) dsl.Pos {
+	if !pos.IsValid() {
+		return pos
+	}
+	f, ok := p.files[pos.File]
+	if !ok {
+		// 解析失败时不补全列号
+		f, _ = parser.ParseFile(p.fset, pos.File, nil, 0)
+		p.files[pos.File] = f
+	}
+	if f != nil {
+		ast.Inspect(f, func(node ast.Node) bool {
+			if pos.Column > 0 {
+				return false
+			}
+			call, ok := node.(*ast.CallExpr)
+			if !ok || p.fset.Position(call.Lparen).Line != pos.Line {
+				return true
+			}
+			switch fun := call.Fun.(type) {
+			case *ast.Ident:
+				ok = fun.Name == name
+			case *ast.SelectorExpr:
+				ok = fun.Sel.Name == name
+			default:
+				ok = false
+			}
+			if ok {
+				pos.Column = p.fset.Position(call.Pos()).Column
+			}
+			return true
+		})
+	}
+	if rel, err := filepath.Rel(p.wd, pos.File); err == nil && !strings.HasPrefix(rel, "..") {
+		pos.File = rel
+	}
+	return pos
+}
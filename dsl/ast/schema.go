@@ -1,28 +1,30 @@
 package ast
 
 import (
-	"github.com/go-kenka/esql/gen"
-	"os"
+	"encoding/json"
 	"path/filepath"
 	"strings"
-)
-
-func ReadDir(path string) []*gen.Table {
-	var tbs []*gen.Table
 
-	files, _ := os.ReadDir(path)
-
-	for _, entry := range files {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
-			return nil
-		}
+	"github.com/go-kenka/esql/dsl"
+	"github.com/go-kenka/esql/gen"
+)
 
-		defBytes, err := os.ReadFile(filepath.Join(path, entry.Name()))
-		if err != nil {
-			return nil
-		}
+// ReadDir 加载schema目录中定义的全部表，定义是否合法需要通过 Validate 检查
+func ReadDir(path string) ([]*gen.Table, error) {
+	exprs, err := load(path)
+	if err != nil {
+		return nil, err
+	}
 
-		tb := astReadFile(entry.Name(), string(defBytes))
+	root, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{pos: newPositions()}
+	var tbs []*gen.Table
+	for _, expr := range exprs {
+		tb := r.readTable(expr)
+		tb.Dir = schemaDir(root, expr.Pos)
 
 		var id *gen.Field
 		var index int
@@ -39,16 +41,20 @@ func ReadDir(path string) []*gen.Table {
 			}
 		}
 
-		// 如果没有ID，需要添加ID
-		if id == nil {
+		switch {
+		case len(tb.PrimaryKey) > 0:
+			// 复合主键的表按定义的顺序保留字段
+		case id == nil:
+			// 如果没有ID，需要添加ID
 			tb.Fields = append([]*gen.Field{
 				{
 					Name:     "id",
 					TypeInfo: gen.TypeInt,
 					Comment:  "Primary key",
+					Pos:      tb.Pos,
 				},
 			}, tb.Fields...)
-		} else {
+		default:
 			//	有ID的，需要将ID调整到首位
 			fields := append(tb.Fields[:index:index], tb.Fields[index+1:]...)
 			tb.Fields = append([]*gen.Field{id}, fields...)
@@ -57,5 +63,189 @@ func ReadDir(path string) []*gen.Table {
 		tbs = append(tbs, tb)
 	}
 
-	return tbs
+	resolveRefs(tbs)
+	return tbs, nil
+}
+
+// schemaDir 表定义所在的目录相对schema根目录的路径，使用 / 分隔，根目录以及无法确定位置时为空
+func schemaDir(root string, pos dsl.Pos) string {
+	if pos.File == "" {
+		return ""
+	}
+	file, err := filepath.Abs(pos.File)
+	if err != nil {
+		return ""
+	}
+	rel, err := filepath.Rel(root, filepath.Dir(file))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// resolveRefs 关联关系指向的表、Ref 字段以及聚合的字段的定义，生成代码时使用其类型
+func resolveRefs(tbs []*gen.Table) {
+	tables := make(map[string]*gen.Table)
+	for _, tb := range tbs {
+		tables[tb.Name] = tb
+	}
+	var resolve func(e *gen.Edge)
+	resolve = func(e *gen.Edge) {
+		if from, ok := tables[e.From]; ok {
+			e.Target = from
+			for _, f := range from.Fields {
+				if f.Name == e.Ref {
+					e.RefField = f
+				}
+				// MIN、MAX 没有指定类型时与聚合的字段类型相同
+				for _, d := range e.Display {
+					if d.Agg != nil && d.Agg.Column == f.Name && d.TypeInfo == dsl.TypeInvalid {
+						d.TypeInfo, d.GoType = f.TypeInfo, f.GoType
+					}
+				}
+			}
+		}
+		for _, r := range e.Relation {
+			resolve(r)
+		}
+		for _, t := range e.Targets {
+			resolve(t)
+		}
+	}
+	for _, tb := range tbs {
+		for _, e := range tb.Edges {
+			resolve(e)
+		}
+	}
+}
+
+// reader 将 dsl 定义转换为生成代码使用的结构
+type reader struct {
+	pos *positions
+}
+
+func (r *reader) readTable(expr *dsl.TableExpr) *gen.Table {
+	fn := "Table"
+	if expr.View {
+		fn = "View"
+	}
+	tb := &gen.Table{
+		Name:     expr.Name,
+		Desc:     expr.Desc,
+		ShardKey: expr.ShardKey,
+		Pos:      r.pos.resolve(expr.Pos, fn),
+
+		PrimaryKey: expr.PrimaryKey,
+
+		Checks:    expr.Checks,
+		Charset:   expr.Charset,
+		Collation: expr.Collation,
+		Engine:    expr.Engine,
+
+		View:     expr.View,
+		ViewSQL:  expr.ViewSQL,
+		External: expr.External,
+	}
+	// 只有 id 的主键与默认情况相同
+	if len(tb.PrimaryKey) == 1 && tb.PrimaryKey[0] == "id" {
+		tb.PrimaryKey = nil
+	}
+	for _, f := range expr.Fields {
+		tb.Fields = append(tb.Fields, r.readField(f))
+	}
+	for _, e := range expr.Edges {
+		tb.Edges = append(tb.Edges, r.readEdge(e))
+	}
+	for _, q := range expr.Queries {
+		query := &gen.Query{
+			Name: q.Name,
+			SQL:  q.SQL,
+			Pos:  r.pos.resolve(q.Pos, "Query"),
+		}
+		for _, f := range q.Fields {
+			query.Fields = append(query.Fields, r.readField(f))
+		}
+		tb.Queries = append(tb.Queries, query)
+	}
+	return tb
+}
+
+func (r *reader) readField(expr *dsl.FieldExpr) *gen.Field {
+	fn := "Field"
+	switch {
+	case expr.Agg != nil && expr.Agg.Func == dsl.AggCount:
+		fn = "Count"
+	case expr.Agg != nil:
+		fn = "Agg"
+	case expr.Expr != "":
+		fn = "Expr"
+	}
+	field := &gen.Field{
+		Tag:      expr.Tag,
+		Name:     expr.Name,
+		Size:     expr.Size,
+		TypeInfo: expr.TypeInfo,
+		Unique:   expr.Unique,
+		Nillable: expr.Nillable,
+		Default:  expr.Default,
+		Comment:  expr.Comment,
+		Pos:      r.pos.resolve(expr.Pos, fn),
+
+		DefaultFunc: expr.DefaultFunc,
+		DefaultExpr: expr.DefaultExpr,
+
+		Enums:    expr.Enums,
+		NullType: expr.NullType,
+
+		GoType:     expr.GoType,
+		SchemaType: expr.SchemaType,
+
+		JSONType: expr.JSONType,
+
+		Validators: expr.Validators,
+
+		Immutable: expr.Immutable,
+		Sensitive: expr.Sensitive,
+		Optional:  expr.Optional,
+
+		Agg:  expr.Agg,
+		Expr: expr.Expr,
+	}
+	// JSON 字段的默认值以JSON字符串的形式保存
+	if expr.TypeInfo == dsl.TypeJSON && expr.Default != nil {
+		if _, ok := expr.Default.(string); !ok {
+			if b, err := json.Marshal(expr.Default); err == nil {
+				field.Default = string(b)
+			}
+		}
+	}
+	return field
+}
+
+func (r *reader) readEdge(expr *dsl.EdgeExpr) *gen.Edge {
+	edge := &gen.Edge{
+		Name:          expr.Name,
+		Type:          expr.Type,
+		Link:          expr.Link,
+		From:          expr.From,
+		Ref:           expr.Ref,
+		Where:         expr.Where,
+		Order:         expr.Order,
+		Limit:         expr.Limit,
+		Discriminator: expr.Discriminator,
+		Value:         expr.Value,
+		Pos:           r.pos.resolve(expr.Pos, "Edge"),
+	}
+	for _, f := range expr.Display {
+		edge.Display = append(edge.Display, r.readField(f))
+	}
+	for _, e := range expr.Relation {
+		edge.Relation = append(edge.Relation, r.readEdge(e))
+	}
+	for _, e := range expr.Targets {
+		target := r.readEdge(e)
+		target.Pos = r.pos.resolve(e.Pos, "Target")
+		edge.Targets = append(edge.Targets, target)
+	}
+	return edge
 }
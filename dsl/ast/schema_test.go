@@ -0,0 +1,73 @@
+package ast
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kenka/esql/gen"
+)
+
+func TestReadDir(t *testing.T) {
+	tbs, err := ReadDir("../../examples/data/schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tbs) != 3 || tbs[0].Name != "access" || tbs[1].Name != "role" || tbs[2].Name != "user" {
+		t.Fatalf("unexpected tables %+v", tbs)
+	}
+
+	role := tbs[1]
+	if p := role.Pos; !strings.HasSuffix(p.File, "role.go") || p.Line != 7 || p.Column != 9 {
+		t.Errorf("unexpected position %s", p)
+	}
+	if role.Fields[0].Name != "id" || role.Fields[1].Size != 20 {
+		t.Errorf("unexpected fields %+v", role.Fields)
+	}
+	if len(role.Edges) != 1 || role.Edges[0].From != "user" || len(role.Edges[0].Display) != 1 {
+		t.Errorf("unexpected edges %+v", role.Edges)
+	}
+}
+
+func TestReadDirRecursive(t *testing.T) {
+	tbs, err := ReadDir("testdata/schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tb := range tbs {
+		names = append(names, tb.Name)
+	}
+	if got := strings.Join(names, ","); got != "user,invoice,payment" {
+		t.Errorf("tables = %s, want user,invoice,payment", got)
+	}
+
+	// 子目录中的表生成到目标目录的同名子目录中
+	if tbs[0].Dir != "" || tbs[1].Dir != "billing" || tbs[2].Dir != "billing" {
+		t.Fatalf("dirs = %q, %q, %q, want \"\", billing, billing", tbs[0].Dir, tbs[1].Dir, tbs[2].Dir)
+	}
+	out := t.TempDir()
+	if err := gen.GenClient(out, "example.com/app/data", tbs); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"user/user.go", "billing/invoice/invoice.go", "billing/invoice/invoice_create.go", "billing/payment/payment_query.go"} {
+		if _, err := os.Stat(filepath.Join(out, file)); err != nil {
+			t.Errorf("generated file %s: %v", file, err)
+		}
+	}
+	for _, dir := range []string{"invoice", "payment"} {
+		if _, err := os.Stat(filepath.Join(out, dir)); !os.IsNotExist(err) {
+			t.Errorf("table %s is generated in the root of the target", dir)
+		}
+	}
+	client, err := os.ReadFile(filepath.Join(out, "client.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, spec := range []string{`"example.com/app/data/user"`, `"example.com/app/data/billing/invoice"`, `"example.com/app/data/billing/payment"`} {
+		if !strings.Contains(string(client), spec) {
+			t.Errorf("client.go does not import %s", spec)
+		}
+	}
+}
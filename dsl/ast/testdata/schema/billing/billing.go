@@ -0,0 +1,12 @@
+package billing
+
+import "github.com/go-kenka/esql/dsl"
+
+var (
+	_ = dsl.Table("invoice",
+		dsl.Fields(dsl.Field("amount", dsl.TypeInfo(dsl.TypeInt64))),
+	)
+	_ = dsl.Table("payment",
+		dsl.Fields(dsl.Field("invoice_id", dsl.TypeInfo(dsl.TypeInt))),
+	)
+)
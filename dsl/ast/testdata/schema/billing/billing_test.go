@@ -0,0 +1,5 @@
+package billing
+
+import "github.com/go-kenka/esql/dsl"
+
+var _ = dsl.Table("ignored")
@@ -0,0 +1,11 @@
+package schema
+
+import (
+	. "github.com/go-kenka/esql/dsl"
+)
+
+var _ = Table("user",
+	Fields(
+		Field("name", TypeInfo(TypeString)),
+	),
+)
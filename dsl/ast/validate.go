@@ -0,0 +1,722 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-kenka/esql/dsl"
+	"github.com/go-kenka/esql/gen"
+)
+
+// Severity 诊断信息的级别
+type Severity uint8
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Diagnostic schema定义中发现的问题
+type Diagnostic struct {
+	Pos      dsl.Pos
+	Severity Severity
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Pos, d.Severity, d.Message)
+}
+
+// Diagnostics 诊断信息列表
+type Diagnostics []Diagnostic
+
+// HasErrors 是否存在错误级别的诊断信息
+func (ds Diagnostics) HasErrors() bool {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 检查schema定义，返回会导致生成代码错误(error)或可能不符合预期(warning)的问题
+func Validate(tbs []*gen.Table) Diagnostics {
+	v := &validator{tables: make(map[string]*gen.Table)}
+	for _, tb := range tbs {
+		if tb.Name == "" {
+			v.errorf(tb.Pos, "table name is empty")
+			continue
+		}
+		if prev, ok := v.tables[tb.Name]; ok {
+			v.errorf(tb.Pos, "table %s is already defined at %s", tb.Name, prev.Pos)
+			continue
+		}
+		v.tables[tb.Name] = tb
+	}
+	for _, tb := range tbs {
+		v.table(tb)
+	}
+	v.goTypes(tbs)
+	return v.diags
+}
+
+type validator struct {
+	tables map[string]*gen.Table
+	diags  Diagnostics
+}
+
+func (v *validator) errorf(pos dsl.Pos, format string, args ...any) {
+	v.diags = append(v.diags, Diagnostic{Pos: pos, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(pos dsl.Pos, format string, args ...any) {
+	v.diags = append(v.diags, Diagnostic{Pos: pos, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) table(tb *gen.Table) {
+	fields := make(map[string]*gen.Field)
+	for _, f := range tb.Fields {
+		if prev, ok := fields[f.Name]; ok && f.Name != "" {
+			v.errorf(f.Pos, "field %s.%s is already defined at %s", tb.Name, f.Name, prev.Pos)
+			continue
+		}
+		fields[f.Name] = f
+		v.field(tb.Name, f)
+	}
+
+	if !gen.IsCompositeKey(tb) {
+		if id := fields["id"]; id != nil && id.Nillable {
+			v.errorf(id.Pos, "primary key %s.id cannot be Nillable", tb.Name)
+		}
+	}
+	keys := make(map[string]bool)
+	for _, name := range tb.PrimaryKey {
+		f := fields[name]
+		switch {
+		case keys[name]:
+			v.errorf(tb.Pos, "primary key column %s of table %s is duplicated", name, tb.Name)
+		case f == nil:
+			v.errorf(tb.Pos, "primary key column %s is not a field of table %s", name, tb.Name)
+		case f.Nillable:
+			v.errorf(f.Pos, "primary key column %s.%s cannot be Nillable", tb.Name, name)
+		case f.TypeInfo == dsl.TypeJSON:
+			v.errorf(f.Pos, "primary key column %s.%s cannot be a JSON field", tb.Name, name)
+		}
+		keys[name] = true
+	}
+	if tb.ShardKey != "" && fields[tb.ShardKey] == nil {
+		v.errorf(tb.Pos, "shard key %s is not a field of table %s", tb.ShardKey, tb.Name)
+	}
+	checks := make(map[string]bool)
+	for _, c := range tb.Checks {
+		switch {
+		case c.Name == "":
+			v.errorf(tb.Pos, "check constraint name is empty in table %s", tb.Name)
+		case checks[c.Name]:
+			v.errorf(tb.Pos, "check constraint %s of table %s is duplicated", c.Name, tb.Name)
+		case strings.TrimSpace(c.Expr) == "":
+			v.errorf(tb.Pos, "check constraint %s of table %s has an empty expression", c.Name, tb.Name)
+		}
+		checks[c.Name] = true
+	}
+	v.view(tb)
+	v.queries(tb)
+
+	edges := make(map[string]*gen.Edge)
+	for _, e := range tb.Edges {
+		if prev, ok := edges[e.Name]; ok {
+			v.errorf(e.Pos, "edge %s.%s is already defined at %s", tb.Name, e.Name, prev.Pos)
+			continue
+		}
+		edges[e.Name] = e
+		v.edge(tb, e)
+	}
+	v.joins(tb)
+	v.tree(tb)
+}
+
+// joins 关系按路径生成常量以及数据结构，例如 role 关系中的 access 使用 EdgeRoleAccess，同一张表中不能重复
+func (v *validator) joins(tb *gen.Table) {
+	path := func(j *gen.Join) string {
+		return strings.ReplaceAll(j.Prefix, "__", ".")
+	}
+	names := make(map[string]*gen.Join)
+	for _, j := range gen.Joins(tb) {
+		prev, ok := names[j.Name]
+		switch {
+		case !ok:
+			names[j.Name] = j
+		case prev.Prefix != j.Prefix:
+			v.errorf(j.Pos, "edge %s.%s generates Edge%s which conflicts with edge %s.%s defined at %s", tb.Name, path(j), j.Name, tb.Name, path(prev), prev.Pos)
+		case j.Parent != nil:
+			v.errorf(j.Pos, "relation %s.%s is already defined at %s", tb.Name, path(j), prev.Pos)
+		}
+	}
+}
+
+// tree 检查自关联的关系，Ancestors、Descendants 只使用其中一个
+func (v *validator) tree(tb *gen.Table) {
+	var self []string
+	for _, e := range tb.Edges {
+		if e.From == tb.Name && e.Type == dsl.TypeM2O && e.Ref == "id" {
+			self = append(self, e.Name)
+		}
+	}
+	switch {
+	case len(self) == 0:
+	case tb.ShardKey != "" || gen.IsCompositeKey(tb):
+		v.warnf(tb.Pos, "Ancestors and Descendants are not generated for table %s, it is sharded or has a composite primary key", tb.Name)
+	case len(self) > 1:
+		v.warnf(tb.Pos, "table %s has several self-referential edges, Ancestors and Descendants follow edge %s", tb.Name, self[0])
+	}
+}
+
+func (v *validator) field(table string, f *gen.Field) {
+	if f.Name == "" {
+		v.errorf(f.Pos, "field name is empty in table %s", table)
+		return
+	}
+	if f.TypeInfo == dsl.TypeInvalid || int(f.TypeInfo) >= len(gen.TypeNames) {
+		v.errorf(f.Pos, "field %s.%s has invalid type %d, set it with TypeInfo", table, f.Name, f.TypeInfo)
+		return
+	}
+	if f.Size != 0 && f.TypeInfo != dsl.TypeString && f.TypeInfo != dsl.TypeEnum && f.TypeInfo != dsl.TypeBytes {
+		v.warnf(f.Pos, "size of field %s.%s is ignored for type %s", table, f.Name, gen.TypeNames[f.TypeInfo])
+	}
+
+	v.enum(table, f)
+	v.validators(table, f)
+	if f.GoType != nil && (f.GoType.PkgPath == "" || f.GoType.Name == "") {
+		v.errorf(f.Pos, "GoType of field %s.%s must have a package path and a type name", table, f.Name)
+	}
+	if f.JSONType != nil && f.TypeInfo != dsl.TypeJSON {
+		v.errorf(f.Pos, "field %s.%s declares a JSON type but its type is %s", table, f.Name, gen.TypeNames[f.TypeInfo])
+	}
+	if f.JSONType != nil && f.GoType != nil {
+		v.errorf(f.Pos, "field %s.%s cannot use both JSON and GoType", table, f.Name)
+	}
+	if f.TypeInfo == dsl.TypeOther && len(f.SchemaType) == 0 {
+		v.errorf(f.Pos, "field %s.%s of type TypeOther must declare its column type with SchemaType", table, f.Name)
+	}
+	if f.NullType == dsl.NullSQL && !gen.SupportsSQLNull(f) {
+		v.warnf(f.Pos, "database/sql has no null type for field %s.%s of type %s, a pointer is generated instead", table, f.Name, gen.TypeNames[f.TypeInfo])
+	}
+	if f.DefaultExpr != "" && f.Default != nil {
+		v.warnf(f.Pos, "default value of field %s.%s is ignored, DefaultExpr takes precedence", table, f.Name)
+	}
+	if f.DefaultFunc != "" && !isExportedFunc(f.DefaultFunc) {
+		v.errorf(f.Pos, "DefaultFunc %s of field %s.%s must be an exported package level function", f.DefaultFunc, table, f.Name)
+	}
+
+	switch d := f.Default.(type) {
+	case nil:
+	case string:
+		if f.TypeInfo == dsl.TypeTime {
+			if _, err := time.Parse("2006-01-02 15:04:05", d); err != nil {
+				v.warnf(f.Pos, "default value %q of field %s.%s is not a time, use DefaultExpr for expressions such as CURRENT_TIMESTAMP", d, table, f.Name)
+			}
+		} else if !gen.IsString(f.TypeInfo) && f.TypeInfo != dsl.TypeJSON && f.TypeInfo != dsl.TypeUUID {
+			v.warnf(f.Pos, "default value %q of field %s.%s does not match type %s", d, table, f.Name, gen.TypeNames[f.TypeInfo])
+		}
+	case bool:
+		if f.TypeInfo != dsl.TypeBool {
+			v.warnf(f.Pos, "default value %t of field %s.%s does not match type %s", d, table, f.Name, gen.TypeNames[f.TypeInfo])
+		}
+	case json.Number:
+		if !gen.IsInt(f.TypeInfo) && f.TypeInfo != dsl.TypeFloat32 && f.TypeInfo != dsl.TypeFloat64 {
+			v.warnf(f.Pos, "default value %s of field %s.%s does not match type %s", d, table, f.Name, gen.TypeNames[f.TypeInfo])
+		}
+	default:
+		// 迁移文件中默认值以字面量输出，只支持基本类型，其他类型的默认值在生成时忽略
+		v.warnf(f.Pos, "default value %v of field %s.%s is not supported and is ignored, use a string, number or bool", d, table, f.Name)
+	}
+}
+
+func (v *validator) edge(tb *gen.Table, e *gen.Edge) {
+	if e.Name == "" {
+		v.errorf(e.Pos, "edge name is empty in table %s", tb.Name)
+		return
+	}
+	if e.Type > dsl.TypePoly {
+		v.errorf(e.Pos, "edge %s.%s has invalid type %d", tb.Name, e.Name, e.Type)
+	}
+	if !hasField(tb, e.Link) {
+		v.errorf(e.Pos, "link column %s of edge %s.%s is not a field of table %s", e.Link, tb.Name, e.Name, tb.Name)
+	}
+	if e.Type == dsl.TypePoly {
+		v.poly(tb, e)
+		return
+	}
+	if e.Discriminator != "" || len(e.Targets) > 0 {
+		v.errorf(e.Pos, "Discriminator and Targets of edge %s.%s require a TypePoly edge", tb.Name, e.Name)
+	}
+
+	from, ok := v.tables[e.From]
+	if !ok {
+		v.errorf(e.Pos, "edge %s.%s points to unknown table %q", tb.Name, e.Name, e.From)
+		return
+	}
+	if !hasField(from, e.Ref) {
+		v.errorf(e.Pos, "ref column %s of edge %s.%s is not a field of table %s", e.Ref, tb.Name, e.Name, from.Name)
+	}
+	// O2M、M2M 关系按主键分组关联数据，Ref 字段的类型必须与主键一致
+	if (e.Type == dsl.TypeO2M || e.Type == dsl.TypeM2M) && gen.IsCompositeKey(tb) {
+		v.errorf(e.Pos, "edge %s.%s is not supported, table %s has a composite primary key", tb.Name, e.Name, tb.Name)
+	} else if (e.Type == dsl.TypeO2M || e.Type == dsl.TypeM2M) && e.RefField != nil && hasField(tb, "id") {
+		if ref, id := gen.RefType(e), gen.IDType(tb); ref != id {
+			v.errorf(e.Pos, "ref column %s.%s of edge %s.%s has type %s, want %s to match %s.id", from.Name, e.Ref, tb.Name, e.Name, ref, id, tb.Name)
+		}
+	}
+	v.condition(tb, e)
+	for _, d := range e.Display {
+		if gen.IsComputed(d) {
+			v.computed(tb, from, e, d)
+			continue
+		}
+		if !hasField(from, d.Name) {
+			v.errorf(d.Pos, "display field %s of edge %s.%s is not a field of table %s", d.Name, tb.Name, e.Name, from.Name)
+			continue
+		}
+		v.field(from.Name, d)
+	}
+	// 关联关系的 Link 字段位于上一级关系指向的表中
+	for _, r := range e.Relation {
+		if r.Type == dsl.TypePoly {
+			v.errorf(r.Pos, "relation %s of edge %s.%s cannot be a polymorphic edge", r.Name, tb.Name, e.Name)
+			continue
+		}
+		v.edge(from, r)
+	}
+}
+
+// poly 检查多态关系，Discriminator 为字符串字段，每张表的 Ref 与 Link 的类型一致，
+// 生成的数据字段以及访问方法不能与表的字段重名
+func (v *validator) poly(tb *gen.Table, e *gen.Edge) {
+	if len(e.Display) > 0 || len(e.Relation) > 0 || len(e.Where) > 0 || len(e.Order) > 0 || e.Limit != 0 {
+		v.errorf(e.Pos, "polymorphic edge %s.%s only supports Display and EdgeWhere on its targets", tb.Name, e.Name)
+	}
+	switch disc := gen.TableField(tb, e.Discriminator); {
+	case e.Discriminator == "":
+		v.errorf(e.Pos, "polymorphic edge %s.%s has no Discriminator", tb.Name, e.Name)
+	case disc == nil:
+		v.errorf(e.Pos, "discriminator column %s of edge %s.%s is not a field of table %s", e.Discriminator, tb.Name, e.Name, tb.Name)
+	case disc.TypeInfo != dsl.TypeString && !gen.IsEnum(disc):
+		v.errorf(e.Pos, "discriminator column %s.%s of edge %s.%s must be a string or enum field", tb.Name, disc.Name, tb.Name, e.Name)
+	}
+	if len(e.Targets) == 0 {
+		v.errorf(e.Pos, "polymorphic edge %s.%s has no Targets", tb.Name, e.Name)
+	}
+
+	names := map[string]string{gen.CamelCase(e.Name): e.Name}
+	values := make(map[string]*gen.Edge)
+	for _, t := range e.Targets {
+		names[gen.CamelCase(e.Name+"_"+t.Name)] = e.Name + "." + t.Name
+		if prev, ok := values[t.Value]; ok {
+			v.errorf(t.Pos, "target %s of edge %s.%s uses value %q of target %s defined at %s", t.Name, tb.Name, e.Name, t.Value, prev.Name, prev.Pos)
+		}
+		values[t.Value] = t
+		if len(t.Relation) > 0 || len(t.Targets) > 0 {
+			v.errorf(t.Pos, "target %s of edge %s.%s cannot declare Relation or Targets", t.Name, tb.Name, e.Name)
+		}
+		v.condition(tb, t)
+		from, ok := v.tables[t.From]
+		if !ok {
+			v.errorf(t.Pos, "target %s of edge %s.%s points to unknown table %q", t.Name, tb.Name, e.Name, t.From)
+			continue
+		}
+		if !hasField(from, t.Ref) {
+			v.errorf(t.Pos, "ref column %s of target %s.%s.%s is not a field of table %s", t.Ref, tb.Name, e.Name, t.Name, from.Name)
+		} else if link := gen.TableField(tb, e.Link); link != nil && gen.RefType(t) != gen.LinkType(tb, e) {
+			v.errorf(t.Pos, "ref column %s.%s of target %s.%s.%s has type %s, want %s to match %s.%s", from.Name, t.Ref, tb.Name, e.Name, t.Name, gen.RefType(t), gen.LinkType(tb, e), tb.Name, e.Link)
+		}
+		for _, d := range t.Display {
+			if gen.IsComputed(d) || !hasField(from, d.Name) {
+				v.errorf(d.Pos, "display field %s of target %s.%s.%s is not a field of table %s", d.Name, tb.Name, e.Name, t.Name, from.Name)
+				continue
+			}
+			v.field(from.Name, d)
+		}
+	}
+	for _, f := range tb.Fields {
+		if name, ok := names[gen.CamelCase(f.Name)]; ok {
+			v.errorf(f.Pos, "field %s.%s conflicts with %s generated for edge %s.%s", tb.Name, f.Name, gen.CamelCase(f.Name), tb.Name, name)
+		}
+	}
+}
+
+// condition 检查关系的 EdgeWhere、EdgeOrder、EdgeLimit，排序以及数量只能用于 O2M、M2M 关系
+func (v *validator) condition(tb *gen.Table, e *gen.Edge) {
+	for _, w := range e.Where {
+		if strings.TrimSpace(w) == "" {
+			v.errorf(e.Pos, "edge %s.%s has an empty EdgeWhere condition", tb.Name, e.Name)
+		}
+	}
+	list := e.Type == dsl.TypeO2M || e.Type == dsl.TypeM2M
+	for _, o := range e.Order {
+		if strings.TrimSpace(o) == "" {
+			v.errorf(e.Pos, "edge %s.%s has an empty EdgeOrder", tb.Name, e.Name)
+		}
+	}
+	if len(e.Order) > 0 && !list {
+		v.errorf(e.Pos, "EdgeOrder of edge %s.%s requires an O2M or M2M edge", tb.Name, e.Name)
+	}
+	switch {
+	case e.Limit < 0:
+		v.errorf(e.Pos, "EdgeLimit of edge %s.%s must be positive, got %d", tb.Name, e.Name, e.Limit)
+	case e.Limit > 0 && !list:
+		v.errorf(e.Pos, "EdgeLimit of edge %s.%s requires an O2M or M2M edge", tb.Name, e.Name)
+	case e.Limit > 0 && len(e.Order) == 0:
+		v.warnf(e.Pos, "EdgeLimit of edge %s.%s without EdgeOrder keeps arbitrary rows", tb.Name, e.Name)
+	}
+}
+
+// computed 检查通过 Count、Agg、Expr 计算得到的 Display 字段，字段位于 tb 的数据结构中，名称不能与其他字段冲突
+func (v *validator) computed(tb, from *gen.Table, e *gen.Edge, d *gen.Field) {
+	if e.Type != dsl.TypeO2M && e.Type != dsl.TypeM2M {
+		v.errorf(d.Pos, "computed display field %s of edge %s.%s requires an O2M or M2M edge", d.Name, tb.Name, e.Name)
+		return
+	}
+	if d.Name == "" {
+		v.errorf(d.Pos, "display field name is empty in edge %s.%s", tb.Name, e.Name)
+		return
+	}
+	if n := promotedNames(tb)[d.Name]; n > 1 || hasField(tb, d.Name) {
+		v.errorf(d.Pos, "computed display field %s of edge %s.%s conflicts with another field of %s", d.Name, tb.Name, e.Name, tb.Name)
+	}
+	switch {
+	case d.Expr != "":
+		if strings.TrimSpace(d.Expr) == "" {
+			v.errorf(d.Pos, "computed display field %s of edge %s.%s has an empty expression", d.Name, tb.Name, e.Name)
+			return
+		}
+	case d.Agg.Func == dsl.AggCount:
+	case d.Agg.Func == dsl.AggSum, d.Agg.Func == dsl.AggAvg, d.Agg.Func == dsl.AggMin, d.Agg.Func == dsl.AggMax:
+		if !hasField(from, d.Agg.Column) {
+			v.errorf(d.Pos, "aggregated column %s of display field %s.%s.%s is not a field of table %s", d.Agg.Column, tb.Name, e.Name, d.Name, from.Name)
+			return
+		}
+	default:
+		v.errorf(d.Pos, "display field %s.%s.%s has unknown aggregate function %q", tb.Name, e.Name, d.Name, d.Agg.Func)
+		return
+	}
+	v.field(from.Name, d)
+}
+
+// promotedNames 关系的数据结构嵌入在表的数据结构中，统计其中字段的名称，同名的字段无法访问
+func promotedNames(tb *gen.Table) map[string]int {
+	names := make(map[string]int)
+	for _, e := range tb.Edges {
+		switch e.Type {
+		case dsl.TypeM2O, dsl.TypeO2O:
+			names[e.Ref]++
+			for _, d := range e.Display {
+				names[d.Name]++
+			}
+		default:
+			for _, d := range gen.StatsFields(e) {
+				names[d.Name]++
+			}
+		}
+	}
+	return names
+}
+
+// view 检查视图的定义，视图只读，写入相关的表选项没有意义
+func (v *validator) view(tb *gen.Table) {
+	if !tb.View {
+		if tb.ViewSQL != "" || tb.External {
+			v.errorf(tb.Pos, "AsSQL and External are only valid for views, declare %s with View", tb.Name)
+		}
+		return
+	}
+	switch {
+	case tb.External && tb.ViewSQL != "":
+		v.warnf(tb.Pos, "query of external view %s is ignored", tb.Name)
+	case !tb.External && strings.TrimSpace(tb.ViewSQL) == "":
+		v.errorf(tb.Pos, "view %s must declare its query with AsSQL or be marked External", tb.Name)
+	}
+	if tb.ShardKey != "" {
+		v.errorf(tb.Pos, "view %s cannot be sharded", tb.Name)
+	}
+	if len(tb.Checks) > 0 || tb.Engine != "" || tb.Charset != "" || tb.Collation != "" {
+		v.warnf(tb.Pos, "table options of view %s are ignored", tb.Name)
+	}
+}
+
+// clientMethods 生成的客户端上已有的方法，命名查询不能与其同名
+var clientMethods = map[string]bool{
+	"WithRouter": true, "WithCache": true, "Query": true, "Get": true,
+	"Create": true, "CreateBulk": true, "Update": true, "UpdateOne": true, "Delete": true, "DeleteOne": true,
+}
+
+// queries 检查命名查询的名称，以及参数、结果列能否对应表的字段
+func (v *validator) queries(tb *gen.Table) {
+	names := make(map[string]*gen.Query)
+	for _, q := range tb.Queries {
+		if prev, ok := names[q.Name]; ok {
+			v.errorf(q.Pos, "query %s.%s is already defined at %s", tb.Name, q.Name, prev.Pos)
+			continue
+		}
+		names[q.Name] = q
+		if !token.IsIdentifier(q.Name) || !token.IsExported(q.Name) {
+			v.errorf(q.Pos, "query name %q of table %s must be an exported Go identifier", q.Name, tb.Name)
+			continue
+		}
+		if clientMethods[q.Name] {
+			v.errorf(q.Pos, "query %s.%s conflicts with the generated client method %s", tb.Name, q.Name, q.Name)
+			continue
+		}
+		for _, f := range q.Fields {
+			v.field(tb.Name, f)
+		}
+		nq, err := gen.ParseQuery(tb, q)
+		if err != nil {
+			v.errorf(q.Pos, "query %s.%s: %v", tb.Name, q.Name, err)
+			continue
+		}
+		if tb.View && !nq.Select {
+			v.errorf(q.Pos, "query %s.%s must be a SELECT, view %s is read-only", tb.Name, q.Name, tb.Name)
+		}
+		if tb.ShardKey != "" {
+			v.warnf(q.Pos, "query %s.%s runs on the default driver, table %s is sharded", tb.Name, q.Name, tb.Name)
+		}
+	}
+}
+
+// validators 检查字段校验规则与字段类型是否匹配
+func (v *validator) validators(table string, f *gen.Field) {
+	isString := f.TypeInfo == dsl.TypeString || f.TypeInfo == dsl.TypeEnum
+	isNumber := gen.IsInt(f.TypeInfo) || f.TypeInfo == dsl.TypeFloat32 || f.TypeInfo == dsl.TypeFloat64
+	for _, r := range f.Validators {
+		switch r.Name {
+		case dsl.ValidatorMaxLen, dsl.ValidatorMinLen, dsl.ValidatorNotEmpty, dsl.ValidatorMatch, dsl.ValidatorEmail:
+			if !isString {
+				v.errorf(f.Pos, "validator %s of field %s.%s requires a string field, got %s", r.Name, table, f.Name, gen.TypeNames[f.TypeInfo])
+			}
+			if r.Len < 0 {
+				v.errorf(f.Pos, "validator %s of field %s.%s has negative length %d", r.Name, table, f.Name, r.Len)
+			}
+			if _, err := regexp.Compile(r.Pattern); err != nil {
+				v.errorf(f.Pos, "validator %s of field %s.%s has invalid pattern: %v", r.Name, table, f.Name, err)
+			}
+		case dsl.ValidatorRange:
+			if !isNumber {
+				v.errorf(f.Pos, "validator %s of field %s.%s requires a numeric field, got %s", r.Name, table, f.Name, gen.TypeNames[f.TypeInfo])
+			}
+			if r.Min > r.Max {
+				v.errorf(f.Pos, "validator %s of field %s.%s has min %v greater than max %v", r.Name, table, f.Name, r.Min, r.Max)
+			}
+		case dsl.ValidatorFunc:
+			if !isExportedFunc(r.Func) {
+				v.errorf(f.Pos, "ValidateFunc %s of field %s.%s must be an exported package level function", r.Func, table, f.Name)
+			}
+		default:
+			v.errorf(f.Pos, "field %s.%s has unknown validator %q", table, f.Name, r.Name)
+		}
+	}
+}
+
+func (v *validator) enum(table string, f *gen.Field) {
+	if f.TypeInfo != dsl.TypeEnum {
+		if len(f.Enums) > 0 {
+			v.errorf(f.Pos, "field %s.%s declares enum values but its type is %s", table, f.Name, gen.TypeNames[f.TypeInfo])
+		}
+		return
+	}
+	if len(f.Enums) == 0 {
+		v.warnf(f.Pos, "enum field %s.%s has no values and is generated as string, declare them with Values", table, f.Name)
+		return
+	}
+	values := make(map[string]bool)
+	consts := make(map[string]bool)
+	for _, e := range f.Enums {
+		name := gen.EnumConst(f, e)
+		switch {
+		case values[e.Value]:
+			v.errorf(f.Pos, "enum value %q of field %s.%s is duplicated", e.Value, table, f.Name)
+		case !token.IsIdentifier(name):
+			v.errorf(f.Pos, "enum value %q of field %s.%s generates invalid constant name %s, use NamedValues", e.Value, table, f.Name, name)
+		case consts[name]:
+			v.errorf(f.Pos, "enum constant %s of field %s.%s is duplicated, use NamedValues", name, table, f.Name)
+		}
+		values[e.Value] = true
+		consts[name] = true
+	}
+	if d, ok := f.Default.(string); ok && !values[d] {
+		v.errorf(f.Pos, "default value %q of field %s.%s is not one of its enum values", d, table, f.Name)
+	}
+}
+
+// isExportedFunc DefaultFunc 是否为导出的包级别函数，闭包、方法的名称形如 pkg.init.func1、pkg.(*T).M
+func isExportedFunc(name string) bool {
+	i := strings.LastIndex(name, "/")
+	parts := strings.Split(name[i+1:], ".")
+	if len(parts) != 2 || strings.ContainsAny(parts[1], "()*-") {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(parts[1])
+	return unicode.IsUpper(r)
+}
+
+func hasField(tb *gen.Table, name string) bool {
+	for _, f := range tb.Fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// goTypes 检查字段的自定义Go类型是否实现了 sql.Scanner 和 driver.Valuer，
+// 类型信息通过 go list -export 在schema所在的目录中加载，同一目录只加载一次
+func (v *validator) goTypes(tbs []*gen.Table) {
+	dirs := make(map[string][]*gen.Field)
+	var order []string
+	tables := make(map[*gen.Field]string)
+	for _, tb := range tbs {
+		for _, f := range tb.Fields {
+			if f.GoType == nil || f.GoType.PkgPath == "" || f.GoType.Name == "" {
+				continue
+			}
+			dir := filepath.Dir(f.Pos.File)
+			if info, err := os.Stat(dir); f.Pos.File == "" || err != nil || !info.IsDir() {
+				dir = ""
+			}
+			if _, ok := dirs[dir]; !ok {
+				order = append(order, dir)
+			}
+			dirs[dir] = append(dirs[dir], f)
+			tables[f] = tb.Name
+		}
+	}
+	for _, dir := range order {
+		fields := dirs[dir]
+		var paths []string
+		for _, f := range fields {
+			paths = append(paths, f.GoType.PkgPath)
+		}
+		imp, err := exportImporter(dir, paths)
+		if err != nil {
+			for _, f := range fields {
+				v.errorf(f.Pos, "cannot load GoType %s.%s of field %s.%s: %v", f.GoType.PkgPath, f.GoType.Name, tables[f], f.Name, err)
+			}
+			continue
+		}
+		scanner, err := lookupInterface(imp, "database/sql", "Scanner")
+		if err != nil {
+			v.errorf(fields[0].Pos, "%v", err)
+			continue
+		}
+		valuer, err := lookupInterface(imp, "database/sql/driver", "Valuer")
+		if err != nil {
+			v.errorf(fields[0].Pos, "%v", err)
+			continue
+		}
+		for _, f := range fields {
+			v.goType(imp, tables[f], f, scanner, valuer)
+		}
+	}
+}
+
+func (v *validator) goType(imp types.Importer, table string, f *gen.Field, scanner, valuer *types.Interface) {
+	name := f.GoType.Name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	pkg, err := imp.Import(f.GoType.PkgPath)
+	if err != nil {
+		v.errorf(f.Pos, "cannot load GoType %s.%s of field %s.%s: %v", f.GoType.PkgPath, f.GoType.Name, table, f.Name, err)
+		return
+	}
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok || !obj.Exported() {
+		v.errorf(f.Pos, "GoType %s.%s of field %s.%s is not an exported type", f.GoType.PkgPath, f.GoType.Name, table, f.Name)
+		return
+	}
+	// 生成的代码通过指针扫描结果，值或者指针接收者的方法都可以
+	typ := types.NewPointer(obj.Type())
+	if !types.Implements(typ, scanner) {
+		v.errorf(f.Pos, "GoType %s.%s of field %s.%s does not implement sql.Scanner", f.GoType.PkgPath, f.GoType.Name, table, f.Name)
+	}
+	if !types.Implements(typ, valuer) {
+		v.errorf(f.Pos, "GoType %s.%s of field %s.%s does not implement driver.Valuer", f.GoType.PkgPath, f.GoType.Name, table, f.Name)
+	}
+}
+
+// exportImporter 通过 go list -export 编译 paths 及其依赖，返回读取编译结果的 types.Importer
+func exportImporter(dir string, paths []string) (types.Importer, error) {
+	args := append([]string{"list", "-e", "-export", "-deps", "-json=ImportPath,Export,Error", "database/sql", "database/sql/driver"}, paths...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	exports := make(map[string]string)
+	errs := make(map[string]string)
+	dec := json.NewDecoder(&stdout)
+	for dec.More() {
+		var pkg struct {
+			ImportPath string
+			Export     string
+			Error      *struct{ Err string }
+		}
+		if err := dec.Decode(&pkg); err != nil {
+			return nil, err
+		}
+		exports[pkg.ImportPath] = pkg.Export
+		if pkg.Error != nil {
+			errs[pkg.ImportPath] = pkg.Error.Err
+		}
+	}
+	return importer.ForCompiler(token.NewFileSet(), "gc", func(path string) (io.ReadCloser, error) {
+		if err, ok := errs[path]; ok {
+			return nil, fmt.Errorf("%s", err)
+		}
+		if exports[path] == "" {
+			return nil, fmt.Errorf("package %s not found", path)
+		}
+		return os.Open(exports[path])
+	}), nil
+}
+
+// lookupInterface 包 path 中名称为 name 的接口
+func lookupInterface(imp types.Importer, path, name string) (*types.Interface, error) {
+	pkg, err := imp.Import(path)
+	if err != nil {
+		return nil, err
+	}
+	obj := pkg.Scope().Lookup(name)
+	if obj == nil {
+		return nil, fmt.Errorf("%s.%s not found", path, name)
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s.%s is not an interface", path, name)
+	}
+	return iface, nil
+}
@@ -0,0 +1,405 @@
+package ast
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-kenka/esql/dsl"
+	"github.com/go-kenka/esql/gen"
+)
+
+func TestValidate(t *testing.T) {
+	pos := dsl.Pos{File: "schema/user.go", Line: 3, Column: 9}
+	tbs := []*gen.Table{
+		{
+			Name: "user",
+			Pos:  pos,
+			Fields: []*gen.Field{
+				{Name: "id", TypeInfo: gen.TypeInt},
+				{Name: "name", TypeInfo: gen.TypeString, Default: json.Number("0")},
+				{Name: "name", TypeInfo: gen.TypeString},
+				{Name: "age"},
+				{Name: "tags", TypeInfo: gen.TypeString, Default: []any{"aaa"}},
+			},
+			Edges: []*gen.Edge{
+				{Name: "access", Link: "access_id", From: "access", Ref: "id", Type: gen.TypeM2O, Pos: pos},
+			},
+		},
+	}
+
+	diags := Validate(tbs)
+	if !diags.HasErrors() {
+		t.Fatal("expected errors")
+	}
+	want := []string{
+		"warning: default value 0 of field user.name does not match type TypeString",
+		"error: field user.name is already defined",
+		"error: field user.age has invalid type 0",
+		"warning: default value [aaa] of field user.tags is not supported and is ignored",
+		"schema/user.go:3:9: error: link column access_id of edge user.access is not a field of table user",
+		"schema/user.go:3:9: error: edge user.access points to unknown table \"access\"",
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(diags), len(want), diags)
+	}
+	for i, d := range diags {
+		if !strings.Contains(d.String(), want[i]) {
+			t.Errorf("diagnostic %d = %q, want %q", i, d, want[i])
+		}
+	}
+}
+
+func TestValidateID(t *testing.T) {
+	id := &gen.Field{Name: "id", TypeInfo: gen.TypeUUID, Nillable: true}
+	ref := &gen.Field{Name: "user_id", TypeInfo: gen.TypeInt}
+	tbs := []*gen.Table{
+		{
+			Name:   "user",
+			Fields: []*gen.Field{id},
+			Edges: []*gen.Edge{
+				{Name: "posts", Link: "id", From: "post", Ref: "user_id", Type: gen.TypeO2M, RefField: ref},
+			},
+		},
+		{
+			Name:   "post",
+			Fields: []*gen.Field{{Name: "id", TypeInfo: gen.TypeInt}, ref},
+		},
+		{
+			Name:       "user_role",
+			PrimaryKey: []string{"user_id", "role_id", "user_id"},
+			Fields: []*gen.Field{
+				{Name: "user_id", TypeInfo: gen.TypeInt, Nillable: true},
+			},
+			Edges: []*gen.Edge{
+				{Name: "posts", Link: "user_id", From: "post", Ref: "user_id", Type: gen.TypeO2M, RefField: ref},
+			},
+		},
+	}
+
+	want := []string{
+		"error: primary key user.id cannot be Nillable",
+		"error: ref column post.user_id of edge user.posts has type int, want uuid.UUID to match user.id",
+		"error: primary key column user_role.user_id cannot be Nillable",
+		"error: primary key column role_id is not a field of table user_role",
+		"error: primary key column user_id of table user_role is duplicated",
+		"error: edge user_role.posts is not supported, table user_role has a composite primary key",
+	}
+	diags := Validate(tbs)
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(diags), len(want), diags)
+	}
+	for i, d := range diags {
+		if !strings.Contains(d.String(), want[i]) {
+			t.Errorf("diagnostic %d = %q, want %q", i, d, want[i])
+		}
+	}
+}
+
+func TestValidateValidators(t *testing.T) {
+	tbs := []*gen.Table{
+		{
+			Name: "user",
+			Fields: []*gen.Field{
+				{Name: "id", TypeInfo: gen.TypeInt, Validators: []*dsl.ValidatorExpr{dsl.Range(10, 1)}},
+				{Name: "name", TypeInfo: gen.TypeString, Validators: []*dsl.ValidatorExpr{
+					dsl.MaxLen(20), {Name: dsl.ValidatorMatch, Pattern: "a("}, dsl.Range(1, 2),
+				}},
+				{Name: "age", TypeInfo: gen.TypeInt, Validators: []*dsl.ValidatorExpr{dsl.NotEmpty()}},
+				{Name: "nick", TypeInfo: gen.TypeString, Validators: []*dsl.ValidatorExpr{
+					{Name: dsl.ValidatorFunc, Func: "example.com/schema.init.func1"},
+				}},
+			},
+		},
+	}
+
+	want := []string{
+		"error: validator Range of field user.id has min 10 greater than max 1",
+		"error: validator Match of field user.name has invalid pattern",
+		"error: validator Range of field user.name requires a numeric field, got TypeString",
+		"error: validator NotEmpty of field user.age requires a string field, got TypeInt",
+		"error: ValidateFunc example.com/schema.init.func1 of field user.nick must be an exported package level function",
+	}
+	diags := Validate(tbs)
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(diags), len(want), diags)
+	}
+	for i, d := range diags {
+		if !strings.Contains(d.String(), want[i]) {
+			t.Errorf("diagnostic %d = %q, want %q", i, d, want[i])
+		}
+	}
+}
+
+func TestValidateChecks(t *testing.T) {
+	tbs := []*gen.Table{
+		{
+			Name:   "order",
+			Fields: []*gen.Field{{Name: "id", TypeInfo: gen.TypeInt}},
+			Checks: []dsl.CheckExpr{
+				{Name: "price_non_negative", Expr: "price >= 0"},
+				{Name: "price_non_negative", Expr: "price < 100"},
+				{Expr: "id > 0"},
+				{Name: "empty", Expr: " "},
+			},
+		},
+	}
+
+	want := []string{
+		"error: check constraint price_non_negative of table order is duplicated",
+		"error: check constraint name is empty in table order",
+		"error: check constraint empty of table order has an empty expression",
+	}
+	diags := Validate(tbs)
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(diags), len(want), diags)
+	}
+	for i, d := range diags {
+		if !strings.Contains(d.String(), want[i]) {
+			t.Errorf("diagnostic %d = %q, want %q", i, d, want[i])
+		}
+	}
+}
+
+func TestValidateView(t *testing.T) {
+	id := []*gen.Field{{Name: "id", TypeInfo: gen.TypeInt}}
+	tbs := []*gen.Table{
+		{Name: "user_stats", View: true, Fields: id, ShardKey: "id"},
+		{Name: "order_stats", View: true, External: true, ViewSQL: "SELECT 1", Fields: id, Engine: "InnoDB"},
+		{Name: "user", ViewSQL: "SELECT 1", Fields: id},
+	}
+
+	want := []string{
+		"error: view user_stats must declare its query with AsSQL or be marked External",
+		"error: view user_stats cannot be sharded",
+		"warning: query of external view order_stats is ignored",
+		"warning: table options of view order_stats are ignored",
+		"error: AsSQL and External are only valid for views, declare user with View",
+	}
+	diags := Validate(tbs)
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(diags), len(want), diags)
+	}
+	for i, d := range diags {
+		if !strings.Contains(d.String(), want[i]) {
+			t.Errorf("diagnostic %d = %q, want %q", i, d, want[i])
+		}
+	}
+}
+
+func TestValidateQueries(t *testing.T) {
+	fields := []*gen.Field{{Name: "id", TypeInfo: gen.TypeInt}, {Name: "role_id", TypeInfo: gen.TypeInt}}
+	tbs := []*gen.Table{
+		{Name: "user", Fields: fields, Queries: []*gen.Query{
+			{Name: "ByRole", SQL: "SELECT * FROM user WHERE role_id = :role_id"},
+			{Name: "ByRole", SQL: "SELECT id FROM user"},
+			{Name: "byName", SQL: "SELECT id FROM user"},
+			{Name: "Get", SQL: "SELECT id FROM user"},
+			{Name: "ByTenant", SQL: "SELECT id FROM user WHERE tenant_id = :tenant_id"},
+			{Name: "Total", SQL: "SELECT COUNT(*) FROM user"},
+		}},
+		{Name: "user_stats", View: true, ViewSQL: "SELECT 1", Fields: fields, Queries: []*gen.Query{
+			{Name: "Purge", SQL: "DELETE FROM user_stats"},
+		}},
+	}
+
+	want := []string{
+		"error: query user.ByRole is already defined",
+		"error: query name \"byName\" of table user must be an exported Go identifier",
+		"error: query user.Get conflicts with the generated client method Get",
+		"error: query user.ByTenant: parameter :tenant_id is not a field of table user",
+		"error: query user.Total: cannot determine the name of result column \"COUNT(*)\", alias it with AS",
+		"error: query user_stats.Purge must be a SELECT, view user_stats is read-only",
+	}
+	diags := Validate(tbs)
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(diags), len(want), diags)
+	}
+	for i, d := range diags {
+		if !strings.Contains(d.String(), want[i]) {
+			t.Errorf("diagnostic %d = %q, want %q", i, d, want[i])
+		}
+	}
+}
+
+func TestValidateComputedDisplay(t *testing.T) {
+	tbs := []*gen.Table{
+		{Name: "user", Fields: []*gen.Field{{Name: "id", TypeInfo: gen.TypeInt}, {Name: "role_id", TypeInfo: gen.TypeInt}},
+			Edges: []*gen.Edge{
+				{Name: "role", Type: gen.TypeM2O, Link: "role_id", From: "role", Ref: "id", Display: []*gen.Field{
+					{Name: "user_count", TypeInfo: gen.TypeInt64, Agg: &dsl.AggExpr{Func: dsl.AggCount}},
+				}},
+			}},
+		{Name: "role", Fields: []*gen.Field{{Name: "id", TypeInfo: gen.TypeInt}, {Name: "name", TypeInfo: gen.TypeString}},
+			Edges: []*gen.Edge{
+				{Name: "users", Type: gen.TypeO2M, Link: "id", From: "user", Ref: "role_id", Display: []*gen.Field{
+					{Name: "user_count", TypeInfo: gen.TypeInt64, Agg: &dsl.AggExpr{Func: dsl.AggCount}},
+					{Name: "name", TypeInfo: gen.TypeString, Expr: "MAX(name)"},
+					{Name: "last_login", TypeInfo: gen.TypeTime, Agg: &dsl.AggExpr{Func: dsl.AggMax, Column: "last_login"}},
+					{Name: "median", TypeInfo: gen.TypeInt, Agg: &dsl.AggExpr{Func: "MEDIAN", Column: "id"}},
+					{Name: "blank", TypeInfo: gen.TypeString, Expr: " "},
+				}},
+			}},
+	}
+
+	want := []string{
+		"error: computed display field user_count of edge user.role requires an O2M or M2M edge",
+		"error: computed display field name of edge role.users conflicts with another field of role",
+		"error: aggregated column last_login of display field role.users.last_login is not a field of table user",
+		"error: display field role.users.median has unknown aggregate function \"MEDIAN\"",
+		"error: computed display field blank of edge role.users has an empty expression",
+	}
+	diags := Validate(tbs)
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(diags), len(want), diags)
+	}
+	for i, d := range diags {
+		if !strings.Contains(d.String(), want[i]) {
+			t.Errorf("diagnostic %d = %q, want %q", i, d, want[i])
+		}
+	}
+}
+
+func TestValidateJoins(t *testing.T) {
+	fields := []*gen.Field{
+		{Name: "id", TypeInfo: gen.TypeInt},
+		{Name: "parent_id", TypeInfo: gen.TypeInt, Nillable: true},
+		{Name: "manager_id", TypeInfo: gen.TypeInt, Nillable: true},
+	}
+	tbs := []*gen.Table{
+		{Name: "employee", Fields: fields, Edges: []*gen.Edge{
+			{Name: "parent", Type: gen.TypeM2O, Link: "parent_id", From: "employee", Ref: "id", Relation: []*gen.Edge{
+				{Name: "manager", Type: gen.TypeM2O, Link: "manager_id", From: "employee", Ref: "id"},
+			}},
+			{Name: "parent_manager", Type: gen.TypeM2O, Link: "manager_id", From: "employee", Ref: "id"},
+			{Name: "manager", Type: gen.TypeM2O, Link: "manager_id", From: "employee", Ref: "id"},
+		}},
+	}
+
+	want := []string{
+		"error: edge employee.parent_manager generates EdgeParentManager which conflicts with edge employee.parent.manager",
+		"warning: table employee has several self-referential edges, Ancestors and Descendants follow edge parent",
+	}
+	diags := Validate(tbs)
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(diags), len(want), diags)
+	}
+	for i, d := range diags {
+		if !strings.Contains(d.String(), want[i]) {
+			t.Errorf("diagnostic %d = %q, want %q", i, d, want[i])
+		}
+	}
+}
+
+func TestValidateEdgeCondition(t *testing.T) {
+	tbs := []*gen.Table{
+		{Name: "user", Fields: []*gen.Field{{Name: "id", TypeInfo: gen.TypeInt}, {Name: "role_id", TypeInfo: gen.TypeInt}},
+			Edges: []*gen.Edge{
+				{Name: "role", Type: gen.TypeM2O, Link: "role_id", From: "role", Ref: "id", Where: []string{"status = 'active'"}, Order: []string{"id"}, Limit: 1},
+				{Name: "addresses", Type: gen.TypeO2M, Link: "id", From: "address", Ref: "user_id", Where: []string{" "}, Limit: 3},
+				{Name: "recent", Type: gen.TypeO2M, Link: "id", From: "address", Ref: "user_id", Order: []string{"id DESC"}, Limit: -1},
+			}},
+		{Name: "role", Fields: []*gen.Field{{Name: "id", TypeInfo: gen.TypeInt}, {Name: "status", TypeInfo: gen.TypeString}}},
+		{Name: "address", Fields: []*gen.Field{{Name: "id", TypeInfo: gen.TypeInt}, {Name: "user_id", TypeInfo: gen.TypeInt}}},
+	}
+
+	want := []string{
+		"error: EdgeOrder of edge user.role requires an O2M or M2M edge",
+		"error: EdgeLimit of edge user.role requires an O2M or M2M edge",
+		"error: edge user.addresses has an empty EdgeWhere condition",
+		"warning: EdgeLimit of edge user.addresses without EdgeOrder keeps arbitrary rows",
+		"error: EdgeLimit of edge user.recent must be positive, got -1",
+	}
+	diags := Validate(tbs)
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(diags), len(want), diags)
+	}
+	for i, d := range diags {
+		if !strings.Contains(d.String(), want[i]) {
+			t.Errorf("diagnostic %d = %q, want %q", i, d, want[i])
+		}
+	}
+}
+
+func TestValidatePoly(t *testing.T) {
+	tbs := []*gen.Table{
+		{Name: "comment", Fields: []*gen.Field{
+			{Name: "id", TypeInfo: gen.TypeInt},
+			{Name: "owner_type", TypeInfo: gen.TypeInt},
+			{Name: "owner_id", TypeInfo: gen.TypeInt},
+			{Name: "owner_post", TypeInfo: gen.TypeString},
+		}, Edges: []*gen.Edge{
+			{Name: "owner", Type: gen.TypePoly, Link: "owner_id", Discriminator: "owner_type", Targets: []*gen.Edge{
+				{Name: "post", Type: gen.TypeM2O, From: "post", Ref: "id", Value: "post", Display: []*gen.Field{{Name: "title", TypeInfo: gen.TypeString}}},
+				{Name: "article", Type: gen.TypeM2O, From: "article", Ref: "uuid", Value: "post"},
+				{Name: "video", Type: gen.TypeM2O, From: "video", Ref: "id", Value: "video"},
+			}},
+			{Name: "author", Type: gen.TypeM2O, Link: "owner_id", From: "post", Ref: "id", Discriminator: "owner_type"},
+		}},
+		{Name: "post", Fields: []*gen.Field{{Name: "id", TypeInfo: gen.TypeInt}}},
+		{Name: "article", Fields: []*gen.Field{{Name: "id", TypeInfo: gen.TypeInt}, {Name: "uuid", TypeInfo: gen.TypeString}}},
+	}
+	for _, tb := range tbs {
+		for _, e := range tb.Edges {
+			for _, t := range e.Targets {
+				for _, from := range tbs {
+					if from.Name == t.From {
+						t.RefField = gen.TableField(from, t.Ref)
+					}
+				}
+			}
+		}
+	}
+
+	want := []string{
+		"error: discriminator column comment.owner_type of edge comment.owner must be a string or enum field",
+		"error: display field title of target comment.owner.post is not a field of table post",
+		"error: target article of edge comment.owner uses value \"post\" of target post",
+		"error: ref column article.uuid of target comment.owner.article has type string, want int to match comment.owner_id",
+		"error: target video of edge comment.owner points to unknown table \"video\"",
+		"error: field comment.owner_post conflicts with OwnerPost generated for edge comment.owner.post",
+		"error: Discriminator and Targets of edge comment.author require a TypePoly edge",
+	}
+	diags := Validate(tbs)
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(diags), len(want), diags)
+	}
+	for i, d := range diags {
+		if !strings.Contains(d.String(), want[i]) {
+			t.Errorf("diagnostic %d = %q, want %q", i, d, want[i])
+		}
+	}
+}
+
+func TestValidateGoType(t *testing.T) {
+	tbs := []*gen.Table{
+		{
+			Name: "wallet",
+			Fields: []*gen.Field{
+				{Name: "id", TypeInfo: gen.TypeInt},
+				{Name: "balance", TypeInfo: gen.TypeString, GoType: &dsl.GoTypeInfo{PkgPath: "database/sql", Name: "NullString"}},
+				{Name: "owner", TypeInfo: gen.TypeString, GoType: &dsl.GoTypeInfo{PkgPath: "database/sql", Name: "sql.NullInt64"}},
+				{Name: "created_at", TypeInfo: gen.TypeString, GoType: &dsl.GoTypeInfo{PkgPath: "time", Name: "Time"}},
+				{Name: "size", TypeInfo: gen.TypeString, GoType: &dsl.GoTypeInfo{PkgPath: "os", Name: "FileMode"}},
+				{Name: "unknown", TypeInfo: gen.TypeString, GoType: &dsl.GoTypeInfo{PkgPath: "time", Name: "Decimal"}},
+			},
+		},
+	}
+
+	want := []string{
+		"error: GoType time.Time of field wallet.created_at does not implement sql.Scanner",
+		"error: GoType time.Time of field wallet.created_at does not implement driver.Valuer",
+		"error: GoType os.FileMode of field wallet.size does not implement sql.Scanner",
+		"error: GoType os.FileMode of field wallet.size does not implement driver.Valuer",
+		"error: GoType time.Decimal of field wallet.unknown is not an exported type",
+	}
+	diags := Validate(tbs)
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %v", len(diags), len(want), diags)
+	}
+	for i, d := range diags {
+		if !strings.Contains(d.String(), want[i]) {
+			t.Errorf("diagnostic %d = %q, want %q", i, d, want[i])
+		}
+	}
+}
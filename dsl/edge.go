@@ -8,6 +8,14 @@ type EdgeExpr struct {
 	Ref      string
 	Display  []*FieldExpr
 	Relation []*EdgeExpr
+	Where    []string // 关联数据的条件，多个时使用 AND 连接
+	Order    []string // O2M、M2M 关联数据的排序
+	Limit    int      // O2M、M2M 每条数据最多关联的数量，0 表示不限制
+	// Discriminator 多态关系中区分指向的表的字段
+	Discriminator string
+	Targets       []*EdgeExpr // 多态关系指向的表
+	Value         string      // 多态关系指向的表在 Discriminator 字段中的值
+	Pos           Pos         // 定义位置
 }
 
 type EdgeFn func(e *EdgeExpr)
@@ -15,6 +23,7 @@ type EdgeFn func(e *EdgeExpr)
 func Edge(name string, fns ...EdgeFn) *EdgeExpr {
 	t := &EdgeExpr{
 		Name: name,
+		Pos:  caller(),
 	}
 
 	for _, fn := range fns {
@@ -58,3 +67,127 @@ func Relation(r ...*EdgeExpr) EdgeFn {
 		e.Relation = append(e.Relation, r...)
 	}
 }
+
+// EdgeWhere 关联数据的条件，使用关系指向的表中的字段，例如 EdgeWhere("deleted_at IS NULL")，
+// M2O、O2O 关系添加到左连接的 ON 中，O2M、M2M 关系添加到查询关联数据的 WHERE 中
+func EdgeWhere(cond string) EdgeFn {
+	return func(e *EdgeExpr) {
+		e.Where = append(e.Where, cond)
+	}
+}
+
+// EdgeOrder O2M、M2M 关联数据的排序，例如 EdgeOrder("created_at DESC")
+func EdgeOrder(order ...string) EdgeFn {
+	return func(e *EdgeExpr) {
+		e.Order = append(e.Order, order...)
+	}
+}
+
+// EdgeLimit O2M、M2M 每条数据最多关联 n 条数据，与 EdgeOrder 一起使用，通过 ROW_NUMBER() 窗口函数在数据库中截取
+func EdgeLimit(n int) EdgeFn {
+	return func(e *EdgeExpr) {
+		e.Limit = n
+	}
+}
+
+// Discriminator 多态关系中区分指向的表的字段，例如 owner_type，Link 为关联的ID字段
+func Discriminator(column string) EdgeFn {
+	return func(e *EdgeExpr) {
+		e.Discriminator = column
+	}
+}
+
+// Targets 多态关系指向的表
+func Targets(t ...*EdgeExpr) EdgeFn {
+	return func(e *EdgeExpr) {
+		e.Targets = append(e.Targets, t...)
+	}
+}
+
+// Target 多态关系指向的表，默认通过 id 关联，Discriminator 字段的值为表名，
+// 可以通过 Ref、PolyValue、Display、EdgeWhere 修改
+func Target(table string, fns ...EdgeFn) *EdgeExpr {
+	t := &EdgeExpr{
+		Name:  table,
+		Type:  TypeM2O,
+		From:  table,
+		Ref:   "id",
+		Value: table,
+		Pos:   caller(),
+	}
+
+	for _, fn := range fns {
+		fn(t)
+	}
+	return t
+}
+
+// PolyValue 多态关系指向的表在 Discriminator 字段中的值，例如 PolyValue("App\\Post")
+func PolyValue(v string) EdgeFn {
+	return func(e *EdgeExpr) {
+		e.Value = v
+	}
+}
+
+// 聚合函数的名称
+const (
+	AggCount = "COUNT"
+	AggSum   = "SUM"
+	AggAvg   = "AVG"
+	AggMin   = "MIN"
+	AggMax   = "MAX"
+)
+
+// AggExpr 聚合函数，Column 为关系指向的表中的字段，COUNT 时为空
+type AggExpr struct {
+	Func   string
+	Column string
+}
+
+// Count 关联数据的条数，只能用于 O2M、M2M 关系的 Display，例如 Display(Count("post_count"))
+func Count(name string, fns ...Fn) *FieldExpr {
+	return computed(&FieldExpr{Name: name, TypeInfo: TypeInt64, Agg: &AggExpr{Func: AggCount}, Pos: caller()}, fns)
+}
+
+// Agg 对关联数据的字段执行聚合，只能用于 O2M、M2M 关系的 Display，例如 Agg("max_login", Max("last_login"))，
+// Sum、Avg 默认为 TypeFloat64，Min、Max 默认与聚合的字段类型相同，可以通过 TypeInfo 修改
+func Agg(name string, agg *AggExpr, fns ...Fn) *FieldExpr {
+	f := &FieldExpr{Name: name, Agg: agg, Pos: caller()}
+	if agg != nil && (agg.Func == AggSum || agg.Func == AggAvg) {
+		f.TypeInfo = TypeFloat64
+	}
+	return computed(f, fns)
+}
+
+// Expr 使用SQL表达式计算的字段，只能用于 O2M、M2M 关系的 Display，表达式在按 Ref 分组的子查询中执行，
+// 例如 Expr("titles", "GROUP_CONCAT(title)")，默认为 TypeString
+func Expr(name, expr string, fns ...Fn) *FieldExpr {
+	return computed(&FieldExpr{Name: name, TypeInfo: TypeString, Expr: expr, Pos: caller()}, fns)
+}
+
+func computed(f *FieldExpr, fns []Fn) *FieldExpr {
+	for _, fn := range fns {
+		fn(f)
+	}
+	return f
+}
+
+// Sum 字段的和
+func Sum(column string) *AggExpr {
+	return &AggExpr{Func: AggSum, Column: column}
+}
+
+// Avg 字段的平均值
+func Avg(column string) *AggExpr {
+	return &AggExpr{Func: AggAvg, Column: column}
+}
+
+// Min 字段的最小值
+func Min(column string) *AggExpr {
+	return &AggExpr{Func: AggMin, Column: column}
+}
+
+// Max 字段的最大值
+func Max(column string) *AggExpr {
+	return &AggExpr{Func: AggMax, Column: column}
+}
@@ -1,5 +1,13 @@
 package dsl
 
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
 type Fn func(f *FieldExpr)
 
 type FieldExpr struct {
@@ -11,11 +19,51 @@ type FieldExpr struct {
 	Nillable bool        // 是否为NULL
 	Default  interface{} // 默认值
 	Comment  string      // 备注
+	Pos      Pos         // 定义位置
+
+	DefaultFunc string // 默认值函数的完整名称，例如 time.Now
+	DefaultExpr string // 数据库默认值表达式，例如 CURRENT_TIMESTAMP
+
+	Enums    []EnumValue // 枚举字段的取值
+	NullType NullKind    // 可为NULL时在结构体中的表示方式
+
+	GoType     *GoTypeInfo       // 自定义的Go类型
+	SchemaType map[string]string // 各数据库方言中的列类型
+
+	JSONType *JSONTypeInfo // 类型化的JSON字段的Go类型
+
+	Validators []*ValidatorExpr // 写入数据前执行的字段校验
+
+	Immutable bool // 创建后不能修改
+	Sensitive bool // 敏感字段，不输出到 String、JSON 以及查询日志
+	Optional  bool // 创建时可以不赋值
+
+	Agg  *AggExpr // 关系中计算得到的 Display 字段使用的聚合函数
+	Expr string   // 关系中计算得到的 Display 字段使用的SQL表达式
+}
+
+// GoTypeInfo 字段在生成代码中使用的自定义Go类型
+type GoTypeInfo struct {
+	PkgPath string // 包路径，内置类型为空
+	Name    string // 类型名称，例如 Decimal
+}
+
+// JSONTypeInfo 类型化的JSON字段在生成代码中使用的Go类型
+type JSONTypeInfo struct {
+	Type    string            // 类型表达式，例如 *schema.Address、[]string
+	Imports map[string]string // 类型引用的包，包名 -> 包路径
+}
+
+// EnumValue 枚举取值，Name 为生成的Go常量名称，为空时由 Value 转换
+type EnumValue struct {
+	Name  string
+	Value string
 }
 
 func Field(name string, fns ...Fn) *FieldExpr {
 	f := &FieldExpr{
 		Name: name,
+		Pos:  caller(),
 	}
 	for _, o := range fns {
 		o(f)
@@ -53,12 +101,152 @@ func Nillable(nillable bool) Fn {
 	}
 }
 
+// Immutable 字段创建后不能修改，生成的 Update、UpdateOne 修改该字段时 Save 返回错误
+func Immutable() Fn {
+	return func(f *FieldExpr) {
+		f.Immutable = true
+	}
+}
+
+// Sensitive 敏感字段，例如密码，不输出到生成结构体的 String、JSON 中，查询日志中的参数输出为 ***
+func Sensitive() Fn {
+	return func(f *FieldExpr) {
+		f.Sensitive = true
+	}
+}
+
+// Optional 创建时可以不赋值。可为NULL以及设置了默认值的字段同样可以不赋值，
+// 其余字段在生成的 Create 中必须赋值，否则 Save 返回错误
+func Optional() Fn {
+	return func(f *FieldExpr) {
+		f.Optional = true
+	}
+}
+
+// NullType 设置可为NULL字段在生成的结构体中的表示方式，同时将字段设置为可为NULL
+func NullType(kind NullKind) Fn {
+	return func(f *FieldExpr) {
+		f.Nillable = true
+		f.NullType = kind
+	}
+}
+
+// GoType 字段使用自定义的Go类型，类型需要实现 sql.Scanner 和 driver.Valuer，
+// 例如 GoType("github.com/shopspring/decimal", "Decimal")。没有设置 TypeInfo 时字段类型为 TypeOther
+func GoType(pkgPath, typeName string) Fn {
+	return func(f *FieldExpr) {
+		if f.TypeInfo == TypeInvalid {
+			f.TypeInfo = TypeOther
+		}
+		f.GoType = &GoTypeInfo{PkgPath: pkgPath, Name: typeName}
+	}
+}
+
+// SchemaType 各数据库方言中字段的列类型，迁移时使用，例如 map[string]string{dialect.MySQL: "decimal(10,2)"}
+func SchemaType(types map[string]string) Fn {
+	return func(f *FieldExpr) {
+		f.SchemaType = types
+	}
+}
+
+// Default 数据库默认值，支持字符串、布尔、整数、浮点数以及 time.Time，
+// JSON 字段可以使用任意能够序列化为JSON的值
 func Default(def interface{}) Fn {
 	return func(f *FieldExpr) {
+		if t, ok := def.(time.Time); ok {
+			def = t.Format("2006-01-02 15:04:05")
+		}
 		f.Default = def
 	}
 }
 
+// DefaultFunc 创建数据时字段没有赋值，调用 fn 生成默认值。
+// fn 必须是导出的包级别函数，没有参数并且只有一个返回值，例如 time.Now、uuid.New
+func DefaultFunc(fn interface{}) Fn {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.Type().NumIn() != 0 || v.Type().NumOut() != 1 {
+		panic(fmt.Sprintf("dsl: DefaultFunc expects a func() T, got %T", fn))
+	}
+	name := runtime.FuncForPC(v.Pointer()).Name()
+	return func(f *FieldExpr) {
+		f.DefaultFunc = name
+	}
+}
+
+// Values 枚举字段的取值，字段类型默认为 TypeEnum
+func Values(values ...string) Fn {
+	return func(f *FieldExpr) {
+		if f.TypeInfo == TypeInvalid {
+			f.TypeInfo = TypeEnum
+		}
+		for _, v := range values {
+			f.Enums = append(f.Enums, EnumValue{Value: v})
+		}
+	}
+}
+
+// NamedValues 枚举字段的取值以及对应的Go常量名称，参数按 name、value 交替出现，
+// 例如 NamedValues("Active", "1", "Disabled", "2")
+func NamedValues(nameValues ...string) Fn {
+	if len(nameValues)%2 != 0 {
+		panic("dsl: NamedValues expects name, value pairs")
+	}
+	return func(f *FieldExpr) {
+		if f.TypeInfo == TypeInvalid {
+			f.TypeInfo = TypeEnum
+		}
+		for i := 0; i < len(nameValues); i += 2 {
+			f.Enums = append(f.Enums, EnumValue{Name: nameValues[i], Value: nameValues[i+1]})
+		}
+	}
+}
+
+// JSON 类型化的JSON字段，字段类型为 TypeJSON，生成的结构体使用 typ 的类型，
+// 例如 JSON(&Address{}) 生成 *schema.Address，JSON([]string{}) 生成 []string。
+// 写入时序列化为JSON，查询后反序列化到对应的字段
+func JSON(typ interface{}) Fn {
+	t := reflect.TypeOf(typ)
+	if t == nil {
+		panic("dsl: JSON expects a typed value, got nil")
+	}
+	info := &JSONTypeInfo{Type: t.String(), Imports: map[string]string{}}
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		if t.Name() != "" && t.PkgPath() != "" {
+			if strings.Contains(t.Name(), "[") {
+				panic(fmt.Sprintf("dsl: JSON does not support generic type %s", t))
+			}
+			// reflect 输出的类型以包名限定，例如 schema.Address
+			name := strings.TrimSuffix(t.String(), "."+t.Name())
+			info.Imports[name] = t.PkgPath()
+			return
+		}
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			walk(t.Elem())
+		case reflect.Map:
+			walk(t.Key())
+			walk(t.Elem())
+		case reflect.Struct:
+			for i := 0; i < t.NumField(); i++ {
+				walk(t.Field(i).Type)
+			}
+		}
+	}
+	walk(t)
+	return func(f *FieldExpr) {
+		f.TypeInfo = TypeJSON
+		f.JSONType = info
+	}
+}
+
+// DefaultExpr 数据库默认值表达式，在迁移时原样输出，例如 CURRENT_TIMESTAMP
+func DefaultExpr(expr string) Fn {
+	return func(f *FieldExpr) {
+		f.DefaultExpr = expr
+	}
+}
+
 func Comment(com string) Fn {
 	return func(f *FieldExpr) {
 		f.Comment = com
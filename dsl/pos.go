@@ -0,0 +1,36 @@
+package dsl
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Pos 定义在源码中的位置，Column 由 schema 加载时补全
+type Pos struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Pos) IsValid() bool {
+	return p.File != ""
+}
+
+func (p Pos) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	if p.Column == 0 {
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// caller 返回调用 Table、Field、Edge 的位置
+func caller() Pos {
+	_, file, line, ok := runtime.Caller(2)
+	if !ok {
+		return Pos{}
+	}
+	return Pos{File: file, Line: line}
+}
@@ -1,25 +1,86 @@
 package dsl
 
+import "sync"
+
+var registry struct {
+	sync.Mutex
+	tables []*TableExpr
+}
+
 type TableExpr struct {
-	Name   string       // 表名称
-	Fields []*FieldExpr // 表字段集合
-	Desc   string       // 备注
-	Edges  []*EdgeExpr  // 关系
+	Name     string       // 表名称
+	Fields   []*FieldExpr // 表字段集合
+	Desc     string       // 备注
+	Edges    []*EdgeExpr  // 关系
+	ShardKey string       // 分片键
+	Pos      Pos          // 定义位置
+
+	PrimaryKey []string // 复合主键的字段
+
+	Checks    []CheckExpr // 检查约束
+	Charset   string      // 表的字符集，仅 MySQL
+	Collation string      // 表的排序规则，仅 MySQL
+	Engine    string      // 存储引擎，仅 MySQL
+
+	View     bool   // 是否为视图
+	ViewSQL  string // 视图的查询语句
+	External bool   // 视图由外部管理，迁移时不创建
+
+	Queries []*QueryExpr // 自定义的命名查询
+}
+
+// CheckExpr 表的检查约束
+type CheckExpr struct {
+	Name string // 约束名称
+	Expr string // 约束表达式，例如 price >= 0
+}
+
+// QueryExpr 自定义的命名查询，参数使用 :name 的形式，生成客户端上同名的方法
+type QueryExpr struct {
+	Name   string       // 查询名称，即生成的方法名称
+	SQL    string       // 查询语句，例如 SELECT * FROM user WHERE role_id = :role_id
+	Fields []*FieldExpr // 不属于表的结果列或参数的类型，例如 COUNT(*) AS total
+	Pos    Pos          // 定义位置
 }
 
 type TableFn func(t *TableExpr)
 
 func Table(name string, fns ...TableFn) *TableExpr {
-	t := &TableExpr{
+	return register(&TableExpr{
 		Name: name,
-	}
+		Pos:  caller(),
+	}, fns)
+}
+
+// View 定义只读的数据库视图，生成与 Table 相同的查询接口和数据结构，但不生成 Create、Update、Delete。
+// 视图的字段需要与查询语句的结果列对应，没有 id 列时通过 PrimaryKey 指定主键
+func View(name string, fns ...TableFn) *TableExpr {
+	return register(&TableExpr{
+		Name: name,
+		Pos:  caller(),
+		View: true,
+	}, fns)
+}
 
+func register(t *TableExpr, fns []TableFn) *TableExpr {
 	for _, fn := range fns {
 		fn(t)
 	}
+
+	registry.Lock()
+	registry.tables = append(registry.tables, t)
+	registry.Unlock()
 	return t
 }
 
+// Tables 返回所有通过 Table 定义的表，按定义的先后顺序排列
+func Tables() []*TableExpr {
+	registry.Lock()
+	defer registry.Unlock()
+
+	return append([]*TableExpr(nil), registry.tables...)
+}
+
 func Desc(desc string) TableFn {
 	return func(t *TableExpr) {
 		t.Desc = desc
@@ -35,3 +96,78 @@ func Fields(fs ...*FieldExpr) TableFn {
 		t.Fields = append(t.Fields, fs...)
 	}
 }
+
+// ShardBy 按指定字段水平分片，生成的CRUD会通过 esql.ShardRouter 定位分片
+func ShardBy(key string) TableFn {
+	return func(t *TableExpr) {
+		t.ShardKey = key
+	}
+}
+
+// PrimaryKey 由多个字段组成的复合主键，例如 PrimaryKey("tenant_id", "id")，
+// 此时不会自动添加 id 字段，生成的 Get、UpdateOne、DeleteOne 按主键的全部字段定位数据
+func PrimaryKey(columns ...string) TableFn {
+	return func(t *TableExpr) {
+		t.PrimaryKey = columns
+	}
+}
+
+// Check 添加检查约束，迁移时原样输出，例如 Check("price_non_negative", "price >= 0")
+func Check(name, expr string) TableFn {
+	return func(t *TableExpr) {
+		t.Checks = append(t.Checks, CheckExpr{Name: name, Expr: expr})
+	}
+}
+
+// Charset 表的字符集，例如 utf8mb4，仅 MySQL 生效
+func Charset(charset string) TableFn {
+	return func(t *TableExpr) {
+		t.Charset = charset
+	}
+}
+
+// Collation 表的排序规则，例如 utf8mb4_general_ci，仅 MySQL 生效
+func Collation(collation string) TableFn {
+	return func(t *TableExpr) {
+		t.Collation = collation
+	}
+}
+
+// Engine 表的存储引擎，例如 InnoDB，仅 MySQL 生效
+func Engine(engine string) TableFn {
+	return func(t *TableExpr) {
+		t.Engine = engine
+	}
+}
+
+// AsSQL 视图的查询语句，迁移时执行 CREATE VIEW name AS query
+func AsSQL(query string) TableFn {
+	return func(t *TableExpr) {
+		t.ViewSQL = query
+	}
+}
+
+// External 视图由外部管理，迁移时不创建
+func External() TableFn {
+	return func(t *TableExpr) {
+		t.External = true
+	}
+}
+
+// Queries 添加自定义的命名查询，用于查询构建器难以表达的语句
+func Queries(qs ...*QueryExpr) TableFn {
+	return func(t *TableExpr) {
+		t.Queries = append(t.Queries, qs...)
+	}
+}
+
+// Query 定义命名查询，例如 Query("ActiveUsersByRole", "SELECT * FROM user WHERE role_id = :role_id")。
+// 参数和结果列按名称对应表的字段，不属于表的列通过 fields 声明类型
+func Query(name, sql string, fields ...*FieldExpr) *QueryExpr {
+	return &QueryExpr{
+		Name:   name,
+		SQL:    sql,
+		Fields: fields,
+		Pos:    caller(),
+	}
+}
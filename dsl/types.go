@@ -29,6 +29,14 @@ const (
 	endTypes
 )
 
+// NullKind 可为NULL的字段在生成的结构体中的表示方式
+type NullKind uint8
+
+const (
+	NullPointer NullKind = iota // 指针，例如 *string
+	NullSQL                     // database/sql 中的Null类型，例如 sql.NullString
+)
+
 type EdgeType uint8
 
 const (
@@ -36,4 +44,5 @@ const (
 	TypeO2M
 	TypeM2O
 	TypeM2M
+	TypePoly // 多态关系，通过 Discriminator 字段的值指向 Targets 中的一张表
 )
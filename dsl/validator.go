@@ -0,0 +1,79 @@
+package dsl
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+)
+
+// 字段校验规则的名称
+const (
+	ValidatorMaxLen   = "MaxLen"
+	ValidatorMinLen   = "MinLen"
+	ValidatorNotEmpty = "NotEmpty"
+	ValidatorMatch    = "Match"
+	ValidatorRange    = "Range"
+	ValidatorEmail    = "Email"
+	ValidatorFunc     = "Func"
+)
+
+// ValidatorExpr 字段校验规则，生成的 Create、Update 在 Set 时执行校验，
+// Save 时返回 esql.ValidationError，包含全部校验失败的字段
+type ValidatorExpr struct {
+	Name    string  // 规则名称，例如 MaxLen
+	Len     int     // MaxLen、MinLen 的长度
+	Min     float64 // Range 的最小值
+	Max     float64 // Range 的最大值
+	Pattern string  // Match 的正则表达式
+	Func    string  // ValidateFunc 的完整名称，例如 github.com/app/schema.CheckName
+}
+
+// Validate 设置字段的校验规则，例如 Validate(MaxLen(20), NotEmpty())
+func Validate(validators ...*ValidatorExpr) Fn {
+	return func(f *FieldExpr) {
+		f.Validators = append(f.Validators, validators...)
+	}
+}
+
+// ValidateFunc 使用自定义函数校验字段的值，fn 必须是导出的包级别函数，
+// 形如 func(T) error，字段的值不是 T 或 *T 时不做校验
+func ValidateFunc(fn interface{}) Fn {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.Type().NumIn() != 1 || v.Type().NumOut() != 1 ||
+		v.Type().Out(0) != reflect.TypeOf((*error)(nil)).Elem() {
+		panic(fmt.Sprintf("dsl: ValidateFunc expects a func(T) error, got %T", fn))
+	}
+	name := runtime.FuncForPC(v.Pointer()).Name()
+	return Validate(&ValidatorExpr{Name: ValidatorFunc, Func: name})
+}
+
+// MaxLen 字符串的长度(字符数)不能超过 n
+func MaxLen(n int) *ValidatorExpr {
+	return &ValidatorExpr{Name: ValidatorMaxLen, Len: n}
+}
+
+// MinLen 字符串的长度(字符数)不能小于 n
+func MinLen(n int) *ValidatorExpr {
+	return &ValidatorExpr{Name: ValidatorMinLen, Len: n}
+}
+
+// NotEmpty 字符串不能为空
+func NotEmpty() *ValidatorExpr {
+	return &ValidatorExpr{Name: ValidatorNotEmpty}
+}
+
+// Match 字符串需要匹配正则表达式
+func Match(re *regexp.Regexp) *ValidatorExpr {
+	return &ValidatorExpr{Name: ValidatorMatch, Pattern: re.String()}
+}
+
+// Range 数值需要在 [min, max] 范围内
+func Range(min, max float64) *ValidatorExpr {
+	return &ValidatorExpr{Name: ValidatorRange, Min: min, Max: max}
+}
+
+// Email 字符串需要是合法的邮箱地址
+func Email() *ValidatorExpr {
+	return &ValidatorExpr{Name: ValidatorEmail}
+}
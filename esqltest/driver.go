@@ -0,0 +1,173 @@
+package esqltest
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+)
+
+// fakeDriver database/sql 驱动，所有调用转发到对应的 Mock 上匹配预期
+type fakeDriver struct{}
+
+func (d *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	m, ok := mocks.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("esqltest: mock %q is closed", dsn)
+	}
+	return &conn{mock: m.(*Mock)}, nil
+}
+
+type conn struct {
+	mock *Mock
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	return c.Prepare(query)
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	e, err := c.mock.next(kindBegin, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.base().err; err != nil {
+		return nil, err
+	}
+	return &tx{conn: c}, nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	e, err := c.mock.next(kindQuery, query, values(args))
+	if err != nil {
+		return nil, err
+	}
+	q := e.(*ExpectedQuery)
+	if q.err != nil {
+		return nil, q.err
+	}
+	if q.rows == nil {
+		return &rows{}, nil
+	}
+	return &rows{columns: q.rows.columns, values: q.rows.values}, nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, err := c.mock.next(kindExec, query, values(args))
+	if err != nil {
+		return nil, err
+	}
+	x := e.(*ExpectedExec)
+	if x.err != nil {
+		return nil, x.err
+	}
+	if x.result == nil {
+		return driver.ResultNoRows, nil
+	}
+	return x.result, nil
+}
+
+// CheckNamedValue 接受任意类型的参数，原样交给预期匹配
+func (c *conn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, named(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+type tx struct {
+	conn *conn
+}
+
+func (t *tx) Commit() error {
+	return t.end(kindCommit)
+}
+
+func (t *tx) Rollback() error {
+	return t.end(kindRollback)
+}
+
+func (t *tx) end(k kind) error {
+	e, err := t.conn.mock.next(k, "", nil)
+	if err != nil {
+		return err
+	}
+	return e.base().err
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	pos     int
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.pos])
+	r.pos++
+	return nil
+}
+
+func values(args []driver.NamedValue) []any {
+	vs := make([]any, len(args))
+	for i, arg := range args {
+		vs[i] = arg.Value
+	}
+	return vs
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	nv := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return nv
+}
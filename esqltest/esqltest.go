@@ -0,0 +1,161 @@
+// Package esqltest 提供实现了 esql.Driver 的模拟数据库，用于在没有真实数据库的情况下测试生成的客户端。
+//
+//	mock := esqltest.New(dialect.MySQL)
+//	mock.ExpectQuery("SELECT .* FROM `user`").
+//		WithArgs(1).
+//		WillReturnRows(esqltest.NewRows("id", "username").AddRow(1, "kenka"))
+//
+//	u, err := user.NewUserClient(mock).Query().Where(sql.EQ(user.ColumnId, 1)).First(ctx)
+//
+//	if err := mock.ExpectationsWereMet(); err != nil {
+//		t.Error(err)
+//	}
+package esqltest
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const driverName = "esqltest"
+
+var (
+	registerOnce sync.Once
+	mocks        sync.Map
+	seq          int64
+)
+
+// Mock 模拟数据库，内嵌的 *sqlx.DB 可以直接作为 esql.Driver 使用，
+// 也可以传给生成的 NewClient。预期的语句按声明顺序依次匹配。
+type Mock struct {
+	*sqlx.DB
+	dsn      string
+	mu       sync.Mutex
+	expected []expectation
+	errs     []error
+}
+
+// New 创建模拟数据库，dialect 决定生成SQL的方言，例如 dialect.MySQL
+func New(dialect string) *Mock {
+	registerOnce.Do(func() {
+		sql.Register(driverName, &fakeDriver{})
+	})
+
+	m := &Mock{
+		dsn: fmt.Sprintf("esqltest_%d", atomic.AddInt64(&seq, 1)),
+	}
+	mocks.Store(m.dsn, m)
+
+	db, _ := sql.Open(driverName, m.dsn)
+	m.DB = sqlx.NewDb(db, dialect)
+	return m
+}
+
+// Close 关闭数据库连接
+func (m *Mock) Close() error {
+	mocks.Delete(m.dsn)
+	return m.DB.Close()
+}
+
+// ExpectQuery 预期执行一条查询语句，expr 为匹配SQL的正则表达式
+func (m *Mock) ExpectQuery(expr string) *ExpectedQuery {
+	e := &ExpectedQuery{}
+	e.init(kindQuery, expr)
+	m.expect(e)
+	return e
+}
+
+// ExpectExec 预期执行一条写入语句，expr 为匹配SQL的正则表达式
+func (m *Mock) ExpectExec(expr string) *ExpectedExec {
+	e := &ExpectedExec{}
+	e.init(kindExec, expr)
+	m.expect(e)
+	return e
+}
+
+// ExpectBegin 预期开启事务
+func (m *Mock) ExpectBegin() *ExpectedTx {
+	e := &ExpectedTx{}
+	e.init(kindBegin, "")
+	m.expect(e)
+	return e
+}
+
+// ExpectCommit 预期提交事务
+func (m *Mock) ExpectCommit() *ExpectedTx {
+	e := &ExpectedTx{}
+	e.init(kindCommit, "")
+	m.expect(e)
+	return e
+}
+
+// ExpectRollback 预期回滚事务
+func (m *Mock) ExpectRollback() *ExpectedTx {
+	e := &ExpectedTx{}
+	e.init(kindRollback, "")
+	m.expect(e)
+	return e
+}
+
+// ExpectationsWereMet 检查所有预期是否都已满足，并且没有出现预期之外的调用
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var msgs []string
+	for _, err := range m.errs {
+		msgs = append(msgs, err.Error())
+	}
+	for _, e := range m.expected {
+		if !e.base().triggered {
+			msgs = append(msgs, fmt.Sprintf("there is a remaining expectation which was not matched: %s", e))
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "\n"))
+	}
+	return nil
+}
+
+func (m *Mock) expect(e expectation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expected = append(m.expected, e)
+}
+
+// next 按顺序匹配下一条未触发的预期
+func (m *Mock) next(k kind, query string, args []any) (expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.expected {
+		b := e.base()
+		if b.triggered {
+			continue
+		}
+		if err := b.match(k, query, args); err != nil {
+			err = fmt.Errorf("esqltest: %s, next expectation is %s: %w", call(k, query, args), e, err)
+			m.errs = append(m.errs, err)
+			return nil, err
+		}
+		b.triggered = true
+		return e, nil
+	}
+	err := fmt.Errorf("esqltest: %s was not expected", call(k, query, args))
+	m.errs = append(m.errs, err)
+	return nil, err
+}
+
+func call(k kind, query string, args []any) string {
+	if query == "" {
+		return fmt.Sprintf("call to %s", k)
+	}
+	return fmt.Sprintf("call to %s %q with args %v", k, query, args)
+}
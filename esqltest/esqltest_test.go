@@ -0,0 +1,77 @@
+package esqltest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+)
+
+func TestMock(t *testing.T) {
+	ctx := context.Background()
+	mock := New(dialect.MySQL)
+	defer mock.Close()
+
+	mock.ExpectQuery("SELECT .* FROM `user`").
+		WithArgs(1).
+		WillReturnRows(NewRows("id", "username").AddRow(1, "kenka"))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `user`").
+		WithArgs(AnyArg()).
+		WillReturnResult(NewResult(2, 1))
+	mock.ExpectCommit()
+
+	var user struct {
+		Id       int    `db:"id"`
+		Username string `db:"username"`
+	}
+	query, args := sql.Dialect(mock.DriverName()).Select("id", "username").From(sql.Table("user")).Where(sql.EQ("id", 1)).Query()
+	if err := mock.GetContext(ctx, &user, query, args...); err != nil {
+		t.Fatalf("GetContext() error = %v", err)
+	}
+	if user.Id != 1 || user.Username != "kenka" {
+		t.Errorf("GetContext() = %+v", user)
+	}
+
+	tx, err := mock.BeginTxx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTxx() error = %v", err)
+	}
+	query, args = sql.Dialect(mock.DriverName()).Insert("user").Set("username", "esql").Query()
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+	if id, _ := result.LastInsertId(); id != 2 {
+		t.Errorf("LastInsertId() = %d, want 2", id)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMockUnexpected(t *testing.T) {
+	ctx := context.Background()
+	mock := New(dialect.MySQL)
+	defer mock.Close()
+
+	boom := errors.New("boom")
+	mock.ExpectExec("DELETE FROM `user`").WillReturnError(boom)
+	mock.ExpectExec("DELETE FROM `role`")
+
+	if _, err := mock.ExecContext(ctx, "DELETE FROM `user`"); !errors.Is(err, boom) {
+		t.Errorf("ExecContext() error = %v, want %v", err, boom)
+	}
+	if _, err := mock.ExecContext(ctx, "UPDATE `role` SET `name` = ?", "admin"); err == nil {
+		t.Error("ExecContext() should fail on unexpected query")
+	}
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Error("ExpectationsWereMet() should report the unmatched expectation")
+	}
+}
@@ -0,0 +1,155 @@
+package esqltest_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+	"github.com/go-kenka/esql"
+	"github.com/go-kenka/esql/esqltest"
+	client "github.com/go-kenka/esql/examples/data"
+	"github.com/go-kenka/esql/examples/data/user"
+)
+
+// exact 完整匹配SQL
+func exact(query string) string {
+	return "^" + regexp.QuoteMeta(query) + "$"
+}
+
+func TestExampleClient(t *testing.T) {
+	ctx := context.Background()
+	mock := esqltest.New(dialect.MySQL)
+	defer mock.Close()
+	c := client.NewClient(mock.DB)
+	columns := []string{"id", "username", "nike_name", "role_id"}
+
+	mock.ExpectQuery(exact("SELECT `t1`.`id`, `t1`.`username`, `t1`.`nike_name`, `t1`.`role_id` FROM `user` AS `t1` WHERE `t1`.`id` = ? LIMIT 1")).
+		WithArgs(1).
+		WillReturnRows(esqltest.NewRows(columns...).AddRow(1, "kenka", "Kenka", 2))
+	u, err := c.User.Query().Where(sql.EQ(user.UserTable.C(user.ColumnId), 1)).First(ctx)
+	if err != nil {
+		t.Fatalf("First() error = %v", err)
+	}
+	if u.Id != 1 || u.Username != "kenka" || u.NikeName != "Kenka" || u.RoleId != 2 {
+		t.Errorf("First() = %+v", u)
+	}
+
+	mock.ExpectExec(exact("INSERT INTO `user` (`username`, `nike_name`, `role_id`) VALUES (?, ?, ?)")).
+		WithArgs("esql", "Esql", 2).
+		WillReturnResult(esqltest.NewResult(3, 1))
+	mock.ExpectQuery(exact("SELECT `t1`.`id`, `t1`.`username`, `t1`.`nike_name`, `t1`.`role_id` FROM `user` AS `t1` WHERE `id` = ?")).
+		WithArgs(3).
+		WillReturnRows(esqltest.NewRows(columns...).AddRow(3, "esql", "Esql", 2))
+	u, err = c.User.Create().
+		Set(user.ColumnUsername, "esql").
+		Set(user.ColumnNikeName, "Esql").
+		Set(user.ColumnRoleId, 2).
+		Save(ctx)
+	if err != nil {
+		t.Fatalf("Create().Save() error = %v", err)
+	}
+	if u.Id != 3 || u.Username != "esql" {
+		t.Errorf("Create().Save() = %+v", u)
+	}
+
+	// MySQL 不支持 RETURNING，先查询需要更新的数据，更新后按主键重新查询
+	mock.ExpectQuery(exact("SELECT `id`, `username`, `nike_name`, `role_id` FROM `user` WHERE `role_id` = ?")).
+		WithArgs(2).
+		WillReturnRows(esqltest.NewRows(columns...).AddRow(1, "kenka", "Kenka", 2).AddRow(3, "esql", "Esql", 2))
+	mock.ExpectExec(exact("UPDATE `user` SET `nike_name` = ? WHERE `role_id` = ?")).
+		WithArgs("Admin", 2).
+		WillReturnResult(esqltest.NewResult(0, 2))
+	mock.ExpectQuery(exact("SELECT `id`, `username`, `nike_name`, `role_id` FROM `user` WHERE `id` IN (?, ?)")).
+		WithArgs(1, 3).
+		WillReturnRows(esqltest.NewRows(columns...).AddRow(1, "kenka", "Admin", 2).AddRow(3, "esql", "Admin", 2))
+	data, err := c.User.Update().
+		Set(user.ColumnNikeName, "Admin").
+		Where(sql.EQ(user.ColumnRoleId, 2)).
+		Save(ctx)
+	if err != nil {
+		t.Fatalf("Update().Save() error = %v", err)
+	}
+	if len(data) != 2 || data[1].NikeName != "Admin" {
+		t.Errorf("Update().Save() = %v", data)
+	}
+
+	mock.ExpectExec(exact("UPDATE `user` SET `nike_name` = ? WHERE `id` = ?")).
+		WithArgs("Root", 1).
+		WillReturnResult(esqltest.NewResult(0, 1))
+	mock.ExpectQuery(exact("SELECT `id`, `username`, `nike_name`, `role_id` FROM `user` WHERE `id` = ?")).
+		WithArgs(1).
+		WillReturnRows(esqltest.NewRows(columns...).AddRow(1, "kenka", "Root", 2))
+	u, err = c.User.UpdateOne(1).Set(user.ColumnNikeName, "Root").Save(ctx)
+	if err != nil {
+		t.Fatalf("UpdateOne().Save() error = %v", err)
+	}
+	if u.Id != 1 || u.NikeName != "Root" {
+		t.Errorf("UpdateOne().Save() = %+v", u)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExampleValidation(t *testing.T) {
+	mock := esqltest.New(dialect.MySQL)
+	defer mock.Close()
+	c := client.NewClient(mock.DB)
+
+	// 必填字段没有赋值时不会执行SQL
+	_, err := c.User.Create().Set(user.ColumnNikeName, "Esql").Save(context.Background())
+	if !errors.Is(err, esql.ErrRequired) {
+		t.Fatalf("Create().Save() error = %v, want %v", err, esql.ErrRequired)
+	}
+	if errors.Is(err, esql.ErrImmutable) {
+		t.Errorf("Create().Save() error = %v, should not be %v", err, esql.ErrImmutable)
+	}
+	var verr *esql.ValidationError
+	if !errors.As(err, &verr) || verr.Table != user.TableName {
+		t.Fatalf("Create().Save() error = %v, want ValidationError", err)
+	}
+	var ferr *esql.FieldError
+	if !errors.As(err, &ferr) || ferr.Field != user.ColumnRoleId {
+		t.Errorf("errors.As(FieldError) = %v", ferr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExampleWithRole(t *testing.T) {
+	mock := esqltest.New(dialect.MySQL)
+	defer mock.Close()
+	c := client.NewClient(mock.DB)
+
+	mock.ExpectQuery(exact("SELECT `t1`.`id`, `t1`.`username`, `t1`.`nike_name`, `t1`.`role_id`, `t2`.`id` AS `role__id`, `t2`.`role_name` AS `role__role_name`, `t3`.`id` AS `role__access__id`, `t3`.`access_name` AS `role__access__access_name` FROM `user` AS `t1` LEFT JOIN `role` AS `t2` ON `t1`.`role_id` = `t2`.`id` LEFT JOIN `access` AS `t3` ON `t2`.`access_id` = `t3`.`id`")).
+		WillReturnRows(esqltest.NewRows("id", "username", "nike_name", "role_id", "role__id", "role__role_name", "role__access__id", "role__access__access_name").
+			AddRow(1, "kenka", "Kenka", 2, 2, "admin", 5, "all").
+			AddRow(2, "esql", "Esql", 3, 3, "guest", nil, nil).
+			AddRow(3, "nobody", "Nobody", 9, nil, nil, nil, nil))
+	data, err := c.User.Query().WithRole().AllX(context.Background())
+	if err != nil {
+		t.Fatalf("AllX() error = %v", err)
+	}
+	if len(data) != 3 {
+		t.Fatalf("AllX() = %v", data)
+	}
+	if r := data[0]; !r.HasRole() || !r.UserEdgeRoleData.HasAccess() || *r.UserEdgeRoleData.UserEdgeRoleAccessData.AccessName != "all" {
+		t.Errorf("AllX()[0] = %+v", r.UserEdgeRoleData)
+	}
+	// 左连接没有匹配 access 时，sqlx 仍然会分配嵌入的结构体
+	if r := data[1]; !r.HasRole() || r.UserEdgeRoleData.HasAccess() {
+		t.Errorf("AllX()[1] = %+v", r.UserEdgeRoleData)
+	}
+	// 左连接没有匹配 role
+	if r := data[2]; r.HasRole() {
+		t.Errorf("AllX()[2] = %+v", r.UserEdgeRoleData)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,221 @@
+package esqltest
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+type kind int
+
+const (
+	kindQuery kind = iota
+	kindExec
+	kindBegin
+	kindCommit
+	kindRollback
+)
+
+func (k kind) String() string {
+	return [...]string{"query", "exec", "begin", "commit", "rollback"}[k]
+}
+
+// Argument 自定义的参数匹配器
+type Argument interface {
+	Match(v any) bool
+}
+
+type anyArg struct{}
+
+func (anyArg) Match(any) bool { return true }
+
+// AnyArg 匹配任意参数
+func AnyArg() Argument {
+	return anyArg{}
+}
+
+type expectation interface {
+	base() *expected
+	String() string
+}
+
+type expected struct {
+	kind      kind
+	expr      *regexp.Regexp
+	args      []any
+	checkArgs bool
+	err       error
+	triggered bool
+}
+
+func (e *expected) init(k kind, expr string) {
+	e.kind = k
+	if expr != "" {
+		e.expr = regexp.MustCompile(expr)
+	}
+}
+
+func (e *expected) base() *expected {
+	return e
+}
+
+func (e *expected) String() string {
+	if e.expr == nil {
+		return e.kind.String()
+	}
+	if e.checkArgs {
+		return fmt.Sprintf("%s %q with args %v", e.kind, e.expr, e.args)
+	}
+	return fmt.Sprintf("%s %q", e.kind, e.expr)
+}
+
+func (e *expected) match(k kind, query string, args []any) error {
+	if e.kind != k {
+		return fmt.Errorf("expected %s", e.kind)
+	}
+	if e.expr != nil && !e.expr.MatchString(query) {
+		return fmt.Errorf("query does not match %q", e.expr)
+	}
+	if !e.checkArgs {
+		return nil
+	}
+	if len(args) != len(e.args) {
+		return fmt.Errorf("expected %d args, got %d", len(e.args), len(args))
+	}
+	for i, want := range e.args {
+		if m, ok := want.(Argument); ok {
+			if !m.Match(args[i]) {
+				return fmt.Errorf("arg %d %v does not match", i, args[i])
+			}
+			continue
+		}
+		if !argEqual(want, args[i]) {
+			return fmt.Errorf("arg %d expected %v, got %v", i, want, args[i])
+		}
+	}
+	return nil
+}
+
+// argEqual 比较参数，数值类型统一按驱动类型比较，例如 int 与 int64
+func argEqual(want, got any) bool {
+	if reflect.DeepEqual(want, got) {
+		return true
+	}
+	wv, err := driver.DefaultParameterConverter.ConvertValue(want)
+	if err != nil {
+		return false
+	}
+	gv, err := driver.DefaultParameterConverter.ConvertValue(got)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(wv, gv)
+}
+
+// ExpectedQuery 预期的查询
+type ExpectedQuery struct {
+	expected
+	rows *Rows
+}
+
+// WithArgs 预期的参数，可以使用 AnyArg 或自定义 Argument 匹配
+func (e *ExpectedQuery) WithArgs(args ...any) *ExpectedQuery {
+	e.args = args
+	e.checkArgs = true
+	return e
+}
+
+// WillReturnRows 查询返回的数据
+func (e *ExpectedQuery) WillReturnRows(rows *Rows) *ExpectedQuery {
+	e.rows = rows
+	return e
+}
+
+// WillReturnError 查询返回的错误
+func (e *ExpectedQuery) WillReturnError(err error) *ExpectedQuery {
+	e.err = err
+	return e
+}
+
+// ExpectedExec 预期的写入
+type ExpectedExec struct {
+	expected
+	result driver.Result
+}
+
+// WithArgs 预期的参数，可以使用 AnyArg 或自定义 Argument 匹配
+func (e *ExpectedExec) WithArgs(args ...any) *ExpectedExec {
+	e.args = args
+	e.checkArgs = true
+	return e
+}
+
+// WillReturnResult 写入返回的结果
+func (e *ExpectedExec) WillReturnResult(result driver.Result) *ExpectedExec {
+	e.result = result
+	return e
+}
+
+// WillReturnError 写入返回的错误
+func (e *ExpectedExec) WillReturnError(err error) *ExpectedExec {
+	e.err = err
+	return e
+}
+
+// ExpectedTx 预期的事务操作
+type ExpectedTx struct {
+	expected
+}
+
+// WillReturnError 事务操作返回的错误
+func (e *ExpectedTx) WillReturnError(err error) *ExpectedTx {
+	e.err = err
+	return e
+}
+
+// NewResult 创建写入结果
+func NewResult(lastInsertID, rowsAffected int64) driver.Result {
+	return result{lastInsertID: lastInsertID, rowsAffected: rowsAffected}
+}
+
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// Rows 查询返回的数据
+type Rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+// NewRows 创建查询结果，columns 为返回的列名
+func NewRows(columns ...string) *Rows {
+	return &Rows{columns: columns}
+}
+
+// AddRow 添加一行数据，值的数量需要与列数一致
+func (r *Rows) AddRow(values ...any) *Rows {
+	if len(values) != len(r.columns) {
+		panic(fmt.Sprintf("esqltest: expected %d values, got %d", len(r.columns), len(values)))
+	}
+	row := make([]driver.Value, len(values))
+	for i, v := range values {
+		dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+		if err != nil {
+			panic(fmt.Sprintf("esqltest: unsupported value %v: %s", v, err))
+		}
+		row[i] = dv
+	}
+	r.values = append(r.values, row)
+	return r
+}
@@ -0,0 +1,139 @@
+// Code generated by esql, DO NOT EDIT.
+package access
+
+import (
+	"context"
+	"entgo.io/ent/dialect/sql"
+	"fmt"
+	"github.com/go-kenka/esql"
+	"strings"
+)
+
+const (
+	TableName        = "access"
+	ColumnId         = "id"
+	ColumnAccessName = "access_name"
+)
+
+var (
+	AccessTable = sql.Table(TableName).As("t1")
+)
+
+var Columns = []string{
+	ColumnId,
+	ColumnAccessName,
+}
+
+type AccessClient struct {
+	direct string
+	db     esql.Driver
+	cache  esql.Cache
+}
+
+type AccessData struct {
+	Id         int    `db:"id"`          // 权限ID
+	AccessName string `db:"access_name"` // 权限名称
+}
+
+// String 返回数据的字符串表示，不包含敏感字段
+func (d *AccessData) String() string {
+	var fields []string
+	fields = append(fields, fmt.Sprintf("id=%v", d.Id))
+	fields = append(fields, fmt.Sprintf("access_name=%v", d.AccessName))
+	return "AccessData(" + strings.Join(fields, ", ") + ")"
+}
+
+func NewAccessClient(db esql.Driver) *AccessClient {
+	return &AccessClient{
+		direct: db.DriverName(),
+		db:     db,
+	}
+}
+
+// WithCache 设置查询缓存，通过 Query().Cache(ttl) 开启，
+// 当前客户端执行 Create/Update/Delete 时自动失效该表的缓存
+func (c *AccessClient) WithCache(cache esql.Cache) *AccessClient {
+	nc := *c
+	nc.cache = cache
+	return &nc
+}
+
+func (c *AccessClient) Query() *AccessQuery {
+	var cols []string
+	for _, column := range Columns {
+		cols = append(cols, AccessTable.C(column))
+	}
+	return &AccessQuery{
+		selector: sql.Dialect(c.direct).Select(cols...).From(AccessTable),
+		db:       c.db,
+		cache:    c.cache,
+		with:     map[string]struct{}{},
+	}
+}
+
+// Get 按主键查询数据
+func (c *AccessClient) Get(ctx context.Context, id int) (*AccessData, error) {
+	return c.Query().Where(sql.EQ(ColumnId, id)).First(ctx)
+}
+
+func (c *AccessClient) Create() *AccessCreate {
+	var cols []string
+	for _, column := range Columns {
+		cols = append(cols, AccessTable.C(column))
+	}
+	return &AccessCreate{
+		selector: sql.Dialect(c.direct).Select(cols...).From(AccessTable),
+		builder:  sql.Dialect(c.direct).Insert(TableName),
+		db:       c.db,
+		cache:    c.cache,
+		data:     &AccessData{},
+	}
+}
+
+func (c *AccessClient) CreateBulk(data ...*AccessCreate) *AccessCreateBulk {
+	var cols []string
+	for _, column := range Columns {
+		cols = append(cols, AccessTable.C(column))
+	}
+	return &AccessCreateBulk{
+		selector: sql.Dialect(c.direct).Select(cols...).From(AccessTable),
+		db:       c.db,
+		cache:    c.cache,
+		data:     data,
+	}
+}
+
+func (c *AccessClient) Update() *AccessUpdate {
+	return &AccessUpdate{
+		builder: sql.Dialect(c.direct).Update(TableName),
+		db:      c.db,
+		cache:   c.cache,
+		data:    &AccessData{},
+	}
+}
+
+func (c *AccessClient) UpdateOne(id int) *AccessUpdateOne {
+	return &AccessUpdateOne{
+		builder: sql.Dialect(c.direct).Update(TableName).Where(sql.EQ(ColumnId, id)),
+		key:     sql.EQ(ColumnId, id),
+		db:      c.db,
+		cache:   c.cache,
+		data:    &AccessData{},
+	}
+}
+
+func (c *AccessClient) Delete() *AccessDelete {
+	return &AccessDelete{
+		builder: sql.Dialect(c.direct).Delete(TableName),
+		db:      c.db,
+		cache:   c.cache,
+	}
+}
+
+func (c *AccessClient) DeleteOne(id int) *AccessDeleteOne {
+	return &AccessDeleteOne{
+		builder: sql.Dialect(c.direct).Delete(TableName).Where(sql.EQ(ColumnId, id)),
+		db:      c.db,
+		cache:   c.cache,
+	}
+}
@@ -0,0 +1,139 @@
+// Code generated by esql, DO NOT EDIT.
+package access
+
+import (
+	"context"
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+	"github.com/go-kenka/esql"
+)
+
+type AccessCreate struct {
+	builder  *sql.InsertBuilder
+	selector *sql.Selector
+	db       esql.Driver
+	cache    esql.Cache
+	data     *AccessData
+	id       any
+}
+
+func (c *AccessCreate) Set(column string, v any) *AccessCreate {
+	if column == ColumnId {
+		c.id = v
+	}
+	c.builder.Set(column, v)
+	return c
+}
+
+// Save 执行插入，并重新查询返回插入的数据
+func (c *AccessCreate) Save(ctx context.Context) (*AccessData, error) {
+	id, err := c.sqlSave(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.get(ctx, id)
+}
+
+// Exec 执行插入，不重新查询插入的数据
+func (c *AccessCreate) Exec(ctx context.Context) error {
+	_, err := c.sqlSave(ctx)
+	return err
+}
+
+// sqlSave 执行插入并返回主键：调用方指定了主键时直接使用，
+// 否则 MySQL 通过 LastInsertId 获取，Postgres、SQLite 通过 RETURNING 获取
+func (c *AccessCreate) sqlSave(ctx context.Context) (any, error) {
+	var id any
+	switch {
+	case c.id != nil:
+		query, args := c.sql()
+		if _, err := c.db.ExecContext(ctx, query, args...); err != nil {
+			return nil, err
+		}
+		id = c.id
+	case c.builder.Dialect() == dialect.MySQL:
+		query, args := c.sql()
+		result, err := c.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		id = int(lastID)
+	default:
+		c.builder.Returning(ColumnId)
+		query, args := c.sql()
+		var v int
+		if err := c.db.QueryRowxContext(ctx, query, args...).Scan(&v); err != nil {
+			return nil, err
+		}
+		id = v
+	}
+	if c.cache != nil {
+		c.cache.Invalidate(TableName)
+	}
+	return id, nil
+}
+
+func (c *AccessCreate) sql() (string, []any) {
+	return c.builder.Query()
+}
+
+func (c *AccessCreate) get(ctx context.Context, id any) (*AccessData, error) {
+	query, args := c.selector.Where(sql.EQ(ColumnId, id)).Query()
+	var data AccessData
+
+	err := esql.GetContext(ctx, c.db, &data, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+type AccessCreateBulk struct {
+	db       esql.Driver
+	cache    esql.Cache
+	selector *sql.Selector
+	data     []*AccessCreate
+}
+
+// Save 执行插入，并重新查询返回插入的数据
+func (cb AccessCreateBulk) Save(ctx context.Context) ([]*AccessData, error) {
+	ids, err := cb.sqlSave(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cb.find(ctx, ids)
+}
+
+// Exec 执行插入，不重新查询插入的数据
+func (cb AccessCreateBulk) Exec(ctx context.Context) error {
+	_, err := cb.sqlSave(ctx)
+	return err
+}
+
+// sqlSave 逐条插入，每条数据设置的字段可能不同，因此不复用预编译语句
+func (cb AccessCreateBulk) sqlSave(ctx context.Context) ([]any, error) {
+	var ids []any
+	for _, d := range cb.data {
+		id, err := d.sqlSave(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (cb AccessCreateBulk) find(ctx context.Context, ids []any) ([]*AccessData, error) {
+	query, args := cb.selector.Where(sql.In(ColumnId, ids...)).Query()
+	var data []*AccessData
+	err := esql.SelectContext(ctx, cb.db, &data, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,58 @@
+// Code generated by esql, DO NOT EDIT.
+package access
+
+import (
+	"context"
+	"entgo.io/ent/dialect/sql"
+	"github.com/go-kenka/esql"
+)
+
+type AccessDelete struct {
+	builder *sql.DeleteBuilder
+	db      esql.Driver
+	cache   esql.Cache
+}
+
+func (d *AccessDelete) Where(p *sql.Predicate) *AccessDelete {
+	d.builder.Where(p)
+	return d
+}
+
+func (d *AccessDelete) Exec(ctx context.Context) (int, error) {
+	return d.sqlSave(ctx)
+}
+
+func (d *AccessDelete) sqlSave(ctx context.Context) (int, error) {
+	query, args := d.builder.Query()
+	result, err := d.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	if d.cache != nil {
+		d.cache.Invalidate(TableName)
+	}
+	aff, _ := result.RowsAffected()
+	return int(aff), nil
+}
+
+type AccessDeleteOne struct {
+	builder *sql.DeleteBuilder
+	db      esql.Driver
+	cache   esql.Cache
+}
+
+func (d *AccessDeleteOne) Save(ctx context.Context) error {
+	return d.sqlSave(ctx)
+}
+
+func (d *AccessDeleteOne) sqlSave(ctx context.Context) error {
+	query, args := d.builder.Query()
+	_, err := d.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if d.cache != nil {
+		d.cache.Invalidate(TableName)
+	}
+	return nil
+}
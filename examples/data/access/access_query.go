@@ -0,0 +1,434 @@
+// Code generated by esql, DO NOT EDIT.
+package access
+
+import (
+	"context"
+	"entgo.io/ent/dialect/sql"
+	"github.com/go-kenka/esql"
+	"time"
+)
+
+type AccessQuery struct {
+	selector *sql.Selector
+	db       esql.Driver
+	with     map[string]struct{}
+	cache    esql.Cache
+	ttl      time.Duration
+}
+
+// Select changes the columns selection of the SELECT statement.
+// Empty selection means all columns *.
+func (q *AccessQuery) Select(columns ...string) *AccessQuery {
+	q.selector.Select(columns...)
+	return q
+}
+
+// AppendSelect appends additional columns to the SELECT statement.
+func (q *AccessQuery) AppendSelect(columns ...string) *AccessQuery {
+	q.selector.AppendSelect(columns...)
+	return q
+}
+
+// SelectExpr changes the columns selection of the SELECT statement
+// with custom list of expressions.
+func (q *AccessQuery) SelectExpr(exprs ...sql.Querier) *AccessQuery {
+	q.selector.SelectExpr(exprs...)
+	return q
+}
+
+// AppendSelectExpr appends additional expressions to the SELECT statement.
+func (q *AccessQuery) AppendSelectExpr(exprs ...sql.Querier) *AccessQuery {
+	q.selector.AppendSelectExpr(exprs...)
+	return q
+}
+
+// AppendSelectExprAs appends additional expressions to the SELECT statement with the given name.
+func (q *AccessQuery) AppendSelectExprAs(expr sql.Querier, as string) *AccessQuery {
+	q.selector.AppendSelectExprAs(expr, as)
+	return q
+}
+
+// From sets the source of `FROM` clause.
+func (q *AccessQuery) From(t sql.TableView) *AccessQuery {
+	q.selector.From(t)
+	return q
+}
+
+// AppendFrom appends a new TableView to the `FROM` clause.
+func (q *AccessQuery) AppendFrom(t sql.TableView) *AccessQuery {
+	q.selector.AppendFrom(t)
+	return q
+}
+
+// FromExpr sets the expression of `FROM` clause.
+func (q *AccessQuery) FromExpr(x sql.Querier) *AccessQuery {
+	q.selector.FromExpr(x)
+	return q
+}
+
+// AppendFromExpr appends an expression (Queries) to the `FROM` clause.
+func (q *AccessQuery) AppendFromExpr(x sql.Querier) *AccessQuery {
+	q.selector.AppendFromExpr(x)
+	return q
+}
+
+// Distinct adds the DISTINCT keyword to the `SELECT` statement.
+func (q *AccessQuery) Distinct() *AccessQuery {
+	q.selector.Distinct()
+	return q
+}
+
+// SetDistinct sets explicitly if the returned rows are distinct or indistinct.
+func (q *AccessQuery) SetDistinct(v bool) *AccessQuery {
+	q.selector.SetDistinct(v)
+	return q
+}
+
+// Limit adds the `LIMIT` clause to the `SELECT` statement.
+func (q *AccessQuery) Limit(limit int) *AccessQuery {
+	q.selector.Limit(limit)
+	return q
+}
+
+// Offset adds the `OFFSET` clause to the `SELECT` statement.
+func (q *AccessQuery) Offset(offset int) *AccessQuery {
+	q.selector.Offset(offset)
+	return q
+}
+
+// Where sets or appends the given predicate to the statement.
+func (q *AccessQuery) Where(p *sql.Predicate) *AccessQuery {
+	q.selector.Where(p)
+	return q
+}
+
+// SetP sets explicitly the predicate function for the selector and clear its previous state.
+func (q *AccessQuery) SetP(p *sql.Predicate) *AccessQuery {
+	q.selector.SetP(p)
+	return q
+}
+
+// FromSelect copies the predicate from a selector.
+func (q *AccessQuery) FromSelect(s2 *sql.Selector) *AccessQuery {
+	q.selector.FromSelect(s2)
+	return q
+}
+
+// Not sets the next coming predicate with not.
+func (q *AccessQuery) Not() *AccessQuery {
+	q.selector.Not()
+	return q
+}
+
+// Or sets the next coming predicate with OR operator (disjunction).
+func (q *AccessQuery) Or() *AccessQuery {
+	q.selector.Or()
+	return q
+}
+
+// Join appends a `JOIN` clause to the statement.
+func (q *AccessQuery) Join(t sql.TableView) *AccessQuery {
+	q.selector.Join(t)
+	return q
+}
+
+// LeftJoin appends a `LEFT JOIN` clause to the statement.
+func (q *AccessQuery) LeftJoin(t sql.TableView) *AccessQuery {
+	q.selector.LeftJoin(t)
+	return q
+}
+
+// RightJoin appends a `RIGHT JOIN` clause to the statement.
+func (q *AccessQuery) RightJoin(t sql.TableView) *AccessQuery {
+	q.selector.RightJoin(t)
+	return q
+}
+
+// FullJoin appends a `FULL JOIN` clause to the statement.
+func (q *AccessQuery) FullJoin(t sql.TableView) *AccessQuery {
+	q.selector.FullJoin(t)
+	return q
+}
+
+// Union appends the UNION (DISTINCT) clause to the query.
+func (q *AccessQuery) Union(t sql.TableView) *AccessQuery {
+	q.selector.Union(t)
+	return q
+}
+
+// UnionAll appends the UNION ALL clause to the query.
+func (q *AccessQuery) UnionAll(t sql.TableView) *AccessQuery {
+	q.selector.UnionAll(t)
+	return q
+}
+
+// Except appends the EXCEPT clause to the query.
+func (q *AccessQuery) Except(t sql.TableView) *AccessQuery {
+	q.selector.Except(t)
+	return q
+}
+
+// ExceptAll appends the EXCEPT ALL clause to the query.
+func (q *AccessQuery) ExceptAll(t sql.TableView) *AccessQuery {
+	q.selector.ExceptAll(t)
+	return q
+}
+
+// Intersect appends the INTERSECT clause to the query.
+func (q *AccessQuery) Intersect(t sql.TableView) *AccessQuery {
+	q.selector.Intersect(t)
+	return q
+}
+
+// IntersectAll appends the INTERSECT ALL clause to the query.
+func (q *AccessQuery) IntersectAll(t sql.TableView) *AccessQuery {
+	q.selector.IntersectAll(t)
+	return q
+}
+
+// Prefix prefixes the query with list of queries.
+func (q *AccessQuery) Prefix(queries ...sql.Querier) *AccessQuery {
+	q.selector.Prefix(queries...)
+	return q
+}
+
+// OnP sets or appends the given predicate for the `ON` clause of the statement.
+func (q *AccessQuery) OnP(p *sql.Predicate) *AccessQuery {
+	q.selector.OnP(p)
+	return q
+}
+
+// On sets the `ON` clause for the `JOIN` operation.
+func (q *AccessQuery) On(c1, c2 string) *AccessQuery {
+	q.selector.On(c1, c2)
+	return q
+}
+
+// As give this selection an alias.
+func (q *AccessQuery) As(alias string) *AccessQuery {
+	q.selector.As(alias)
+	return q
+}
+
+// Count sets the Select statement to be a `SELECT COUNT(*)`.
+func (q *AccessQuery) Count(columns ...string) *AccessQuery {
+	q.selector.Count(columns...)
+	return q
+}
+
+// For sets the lock configuration for suffixing the `SELECT`
+// statement with the `FOR [SHARE | UPDATE] ...` clause.
+func (q *AccessQuery) For(l sql.LockStrength, opts ...sql.LockOption) *AccessQuery {
+	q.selector.For(l, opts...)
+	return q
+}
+
+// ForShare sets the lock configuration for suffixing the
+// `SELECT` statement with the `FOR SHARE` clause.
+func (q *AccessQuery) ForShare(opts ...sql.LockOption) *AccessQuery {
+	q.selector.ForShare(opts...)
+	return q
+}
+
+// ForUpdate sets the lock configuration for suffixing the
+// `SELECT` statement with the `FOR UPDATE` clause.
+func (q *AccessQuery) ForUpdate(opts ...sql.LockOption) *AccessQuery {
+	q.selector.ForUpdate(opts...)
+	return q
+}
+
+// OrderBy appends the `ORDER BY` clause to the `SELECT` statement.
+func (q *AccessQuery) OrderBy(columns ...string) *AccessQuery {
+	q.selector.OrderBy(columns...)
+	return q
+}
+
+// OrderExpr appends the `ORDER BY` clause to the `SELECT`
+// statement with custom list of expressions.
+func (q *AccessQuery) OrderExpr(exprs ...sql.Querier) *AccessQuery {
+	q.selector.OrderExpr(exprs...)
+	return q
+}
+
+// ClearOrder clears the ORDER BY clause to be empty.
+func (q *AccessQuery) ClearOrder() *AccessQuery {
+	q.selector.ClearOrder()
+	return q
+}
+
+// GroupBy appends the `GROUP BY` clause to the `SELECT` statement.
+func (q *AccessQuery) GroupBy(columns ...string) *AccessQuery {
+	q.selector.GroupBy(columns...)
+	return q
+}
+
+// Having appends a predicate for the `HAVING` clause.
+func (q *AccessQuery) Having(p *sql.Predicate) *AccessQuery {
+	q.selector.Having(p)
+	return q
+}
+
+func (q *AccessQuery) Query() (string, []any) {
+	return q.selector.Query()
+}
+
+func (q *AccessQuery) C(column string) string {
+	return q.selector.C(column)
+}
+
+func (q *AccessQuery) Clone() *AccessQuery {
+	with := make(map[string]struct{})
+	for k, v := range q.with {
+		with[k] = v
+	}
+	return &AccessQuery{
+		selector: q.selector.Clone(),
+		db:       q.db,
+		with:     with,
+		cache:    q.cache,
+		ttl:      q.ttl,
+	}
+}
+
+func (q *AccessQuery) First(ctx context.Context) (*AccessData, error) {
+	query, args := q.Limit(1).Query()
+	if v, ok := q.cacheGet(query, args); ok {
+		return v.(*AccessData), nil
+	}
+	var data AccessData
+	err := esql.GetContext(ctx, q.db, &data, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.queryWith(ctx, []*AccessData{&data})
+	if err != nil {
+		return nil, err
+	}
+
+	q.cacheSet(query, args, &data)
+	return &data, nil
+}
+
+func (q *AccessQuery) FirstID(ctx context.Context) (int, error) {
+	query, args := q.Select(ColumnId).Limit(1).Query()
+	var id int
+	err := q.db.QueryRowxContext(ctx, query, args...).Scan(&id)
+	return id, err
+}
+
+func (q *AccessQuery) IDs(ctx context.Context) ([]int, error) {
+	query, args := q.Select(ColumnId).Query()
+	rows, err := q.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var data []int
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, id)
+	}
+
+	return data, nil
+}
+
+func (q *AccessQuery) ScanX(ctx context.Context, dist any) error {
+	query, args := q.Query()
+	err := esql.SelectContext(ctx, q.db, dist, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (q *AccessQuery) AllX(ctx context.Context) ([]*AccessData, error) {
+	query, args := q.Query()
+	if v, ok := q.cacheGet(query, args); ok {
+		return v.([]*AccessData), nil
+	}
+	var data []*AccessData
+	err := esql.SelectContext(ctx, q.db, &data, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.queryWith(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	q.cacheSet(query, args, data)
+	return data, nil
+}
+
+func (q *AccessQuery) CountX(ctx context.Context) (int, error) {
+	query, args := q.Count(ColumnId).Query()
+	var count int
+	err := q.db.QueryRowxContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (q *AccessQuery) ExistX(ctx context.Context) (bool, error) {
+	query, args := q.Count(ColumnId).Query()
+	var count int
+	err := q.db.QueryRowxContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Cache 开启查询缓存，First、AllX 的结果缓存 ttl 时长，需要客户端通过 WithCache 设置了缓存
+func (q *AccessQuery) Cache(ttl time.Duration) *AccessQuery {
+	q.ttl = ttl
+	return q
+}
+
+// cacheGet 读取缓存，返回缓存数据的深拷贝
+func (q *AccessQuery) cacheGet(query string, args []any) (any, bool) {
+	if q.cache == nil || q.ttl <= 0 {
+		return nil, false
+	}
+	v, ok := q.cache.Get(q.cacheKey(query, args))
+	if !ok {
+		return nil, false
+	}
+	return esql.DeepCopy(v), true
+}
+
+// cacheSet 写入结果的深拷贝，调用方之后的修改不会影响缓存
+func (q *AccessQuery) cacheSet(query string, args []any, v any) {
+	if q.cache == nil || q.ttl <= 0 {
+		return
+	}
+	q.cache.Set(q.cacheKey(query, args), esql.DeepCopy(v), q.ttl, q.cacheTables()...)
+}
+
+func (q *AccessQuery) cacheKey(query string, args []any) string {
+	var with []string
+	for k := range q.with {
+		with = append(with, k)
+	}
+	return esql.CacheKey(query, args, with...)
+}
+
+// cacheTables 返回查询结果依赖的表，任意一张表发生写入时缓存失效
+func (q *AccessQuery) cacheTables() []string {
+	tables := []string{TableName}
+	return tables
+}
+
+func (q *AccessQuery) queryWith(ctx context.Context, data []*AccessData) error {
+	return nil
+}
@@ -0,0 +1,163 @@
+// Code generated by esql, DO NOT EDIT.
+package access
+
+import (
+	"context"
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+	"github.com/go-kenka/esql"
+)
+
+type AccessUpdate struct {
+	builder *sql.UpdateBuilder
+	where   []*sql.Predicate // MySQL 更新后按条件查询更新前的主键
+	db      esql.Driver
+	cache   esql.Cache
+	data    *AccessData
+}
+
+func (u *AccessUpdate) Set(column string, v any) *AccessUpdate {
+	u.builder.Set(column, v)
+	return u
+}
+func (u *AccessUpdate) SetNull(column string) *AccessUpdate {
+	u.builder.SetNull(column)
+	return u
+}
+func (u *AccessUpdate) Add(column string, v any) *AccessUpdate {
+	u.builder.Add(column, v)
+	return u
+}
+
+func (u *AccessUpdate) Where(p *sql.Predicate) *AccessUpdate {
+	u.where = append(u.where, p)
+	u.builder.Where(p)
+	return u
+}
+
+func (u *AccessUpdate) Save(ctx context.Context) ([]*AccessData, error) {
+	u.builder.Returning(Columns...)
+	return u.sqlSave(ctx)
+}
+
+func (u *AccessUpdate) sqlSave(ctx context.Context) ([]*AccessData, error) {
+	if u.builder.Dialect() == dialect.MySQL {
+		return u.mysqlSave(ctx)
+	}
+	query, args := u.builder.Query()
+	stmt, err := u.db.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	var data []*AccessData
+
+	err = stmt.SelectContext(ctx, &data, args...)
+	if err != nil {
+		return nil, err
+	}
+	if u.cache != nil {
+		u.cache.Invalidate(TableName)
+	}
+	return data, nil
+}
+
+// mysqlSave MySQL 不支持 RETURNING，先按条件查询需要更新的数据，执行更新后按主键重新查询
+func (u *AccessUpdate) mysqlSave(ctx context.Context) ([]*AccessData, error) {
+	selector := sql.Dialect(dialect.MySQL).Select(Columns...).From(sql.Table(TableName))
+	if len(u.where) > 0 {
+		selector.Where(sql.And(u.where...))
+	}
+	query, args := selector.Query()
+	var rows []*AccessData
+	if err := esql.SelectContext(ctx, u.db, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	query, args = u.builder.Query()
+	if _, err := u.db.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+	if u.cache != nil {
+		u.cache.Invalidate(TableName)
+	}
+	ids := make([]any, 0, len(rows))
+	for _, d := range rows {
+		ids = append(ids, d.Id)
+	}
+	query, args = sql.Dialect(dialect.MySQL).Select(Columns...).From(sql.Table(TableName)).Where(sql.In(ColumnId, ids...)).Query()
+	var data []*AccessData
+	if err := esql.SelectContext(ctx, u.db, &data, query, args...); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+type AccessUpdateOne struct {
+	builder *sql.UpdateBuilder
+	key     *sql.Predicate // MySQL 更新后按主键重新查询
+	db      esql.Driver
+	cache   esql.Cache
+	data    *AccessData
+}
+
+func (u *AccessUpdateOne) Set(column string, v any) *AccessUpdateOne {
+	u.builder.Set(column, v)
+	return u
+}
+func (u *AccessUpdateOne) SetNull(column string) *AccessUpdateOne {
+	u.builder.SetNull(column)
+	return u
+}
+func (u *AccessUpdateOne) Add(column string, v any) *AccessUpdateOne {
+	u.builder.Add(column, v)
+	return u
+}
+
+func (u *AccessUpdateOne) Save(ctx context.Context) (*AccessData, error) {
+	u.builder.Returning(Columns...)
+	return u.sqlSave(ctx)
+}
+
+func (u *AccessUpdateOne) sqlSave(ctx context.Context) (*AccessData, error) {
+	if u.builder.Dialect() == dialect.MySQL {
+		return u.mysqlSave(ctx)
+	}
+	query, args := u.builder.Query()
+	stmt, err := u.db.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	var data AccessData
+
+	err = stmt.GetContext(ctx, &data, args...)
+	if err != nil {
+		return nil, err
+	}
+	if u.cache != nil {
+		u.cache.Invalidate(TableName)
+	}
+	return &data, nil
+}
+
+// mysqlSave MySQL 不支持 RETURNING，执行更新后按主键重新查询
+func (u *AccessUpdateOne) mysqlSave(ctx context.Context) (*AccessData, error) {
+	query, args := u.builder.Query()
+	if _, err := u.db.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+	if u.cache != nil {
+		u.cache.Invalidate(TableName)
+	}
+
+	query, args = sql.Dialect(dialect.MySQL).Select(Columns...).From(sql.Table(TableName)).Where(u.key).Query()
+	var data AccessData
+	if err := esql.GetContext(ctx, u.db, &data, query, args...); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/qustavo/sqlhooks/v2"
 	"time"
 
+	"github.com/go-kenka/esql/examples/data/access"
 	"github.com/go-kenka/esql/examples/data/migrate"
 	"github.com/go-kenka/esql/examples/data/role"
 	"github.com/go-kenka/esql/examples/data/user"
@@ -22,40 +23,69 @@ type Client struct {
 	DB      *sqlx.DB
 	Builder *sql.DialectBuilder
 	Schema  *migrate.Schema
+	options options
+	Access  *access.AccessClient
 	Role    *role.RoleClient
 	User    *user.UserClient
 }
 
+// Option 客户端配置项
+type Option func(*options)
+
+type options struct {
+	router esql.ShardRouter
+	cache  esql.Cache
+}
+
+// WithShardRouter 设置分片路由，作用于所有通过 ShardBy 声明的分片表
+func WithShardRouter(router esql.ShardRouter) Option {
+	return func(o *options) {
+		o.router = router
+	}
+}
+
+// WithCache 设置查询缓存，通过 Query().Cache(ttl) 按需开启，
+// 客户端对表执行 Create/Update/Delete 时自动失效相关缓存
+func WithCache(cache esql.Cache) Option {
+	return func(o *options) {
+		o.cache = cache
+	}
+}
+
 // NewClient .
-func NewClient(db *sqlx.DB) *Client {
-	drv := migrate.Driver(db)
+func NewClient(db *sqlx.DB, opts ...Option) *Client {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	drv := migrate.Driver(db.DriverName(), db.DB)
 	return &Client{
 		DB:      db,
 		Builder: sql.Dialect(db.DriverName()),
 		Schema:  migrate.NewSchema(drv),
-		Role:    role.NewRoleClient(db),
-		User:    user.NewUserClient(db),
+		options: *o,
+		Access:  access.NewAccessClient(db).WithCache(o.cache),
+		Role:    role.NewRoleClient(db).WithCache(o.cache),
+		User:    user.NewUserClient(db).WithCache(o.cache),
 	}
 }
 
 // Open .
-func Open(driverName, dataSourceName string) (*Client, error) {
+func Open(driverName, dataSourceName string, opts ...Option) (*Client, error) {
 	switch driverName {
 	case dialect.MySQL, dialect.Postgres, dialect.SQLite:
-		db, err := sql.Open(driverName, dataSourceName)
+		db, err := stdSql.Open(driverName, dataSourceName)
 		if err != nil {
 			return nil, err
 		}
 		driver := fmt.Sprintf("%sWithHooks", driverName)
-		stdSql.Register(driver, sqlhooks.Wrap(db.DB().Driver(), &Hooks{}))
-
-		defer db.Close()
+		stdSql.Register(driver, sqlhooks.Wrap(db.Driver(), &Hooks{}))
 
 		ndb, err := sqlx.Open(driver, dataSourceName)
 		if err != nil {
 			return nil, err
 		}
-		return NewClient(ndb), nil
+		return NewClient(ndb, opts...), nil
 	default:
 		return nil, fmt.Errorf("unsupported driver: %q", driverName)
 	}
@@ -65,7 +95,9 @@ func Open(driverName, dataSourceName string) (*Client, error) {
 type Tx struct {
 	esql.Driver
 	tx      *sqlx.Tx
+	cache   *esql.TxCache
 	Builder *sql.DialectBuilder
+	Access  *access.AccessClient
 	Role    *role.RoleClient
 	User    *user.UserClient
 }
@@ -76,16 +108,25 @@ func (c *Client) BeginTx(ctx context.Context, opts *stdSql.TxOptions) (*Tx, erro
 		return nil, err
 	}
 
+	cache := esql.NewTxCache(c.options.cache)
 	return &Tx{
 		Driver:  tx,
+		tx:      tx,
+		cache:   cache,
 		Builder: sql.Dialect(tx.DriverName()),
-		Role:    role.NewRoleClient(tx),
-		User:    user.NewUserClient(tx),
+		Access:  access.NewAccessClient(tx).WithCache(cache),
+		Role:    role.NewRoleClient(tx).WithCache(cache),
+		User:    user.NewUserClient(tx).WithCache(cache),
 	}, nil
 }
 
+// Commit 提交事务，并使事务中写入的表的缓存失效
 func (t *Tx) Commit() error {
-	return t.tx.Commit()
+	if err := t.tx.Commit(); err != nil {
+		return err
+	}
+	t.cache.Commit()
+	return nil
 }
 
 func (t *Tx) Rollback() error {
@@ -97,7 +138,7 @@ type Hooks struct{}
 
 // Before hook will print the query with it's args and return the context with the timestamp
 func (h *Hooks) Before(ctx context.Context, query string, args ...interface{}) (context.Context, error) {
-	fmt.Printf("> %s %q", query, args)
+	fmt.Printf("> %s %q", query, esql.Redact(ctx, args))
 	return context.WithValue(ctx, "begin", time.Now()), nil
 }
 
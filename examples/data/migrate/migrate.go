@@ -3,9 +3,9 @@ package migrate
 
 import (
 	"context"
+	stdSql "database/sql"
 	"entgo.io/ent/dialect/sql"
 	"fmt"
-	"github.com/jmoiron/sqlx"
 	"io"
 
 	"entgo.io/ent/dialect"
@@ -33,9 +33,9 @@ var (
 	WithForeignKeys = schema.WithForeignKeys
 )
 
-// Driver use sqlx.DB gen Driver
-func Driver(db *sqlx.DB) *sql.Driver {
-	return sql.NewDriver(db.DriverName(), sql.Conn{ExecQuerier: db})
+// Driver use sql.DB gen Driver
+func Driver(driver string, db *stdSql.DB) *sql.Driver {
+	return sql.NewDriver(driver, sql.Conn{ExecQuerier: db})
 }
 
 // Schema is the API for creating, migrating and dropping a schema.
@@ -48,7 +48,10 @@ func NewSchema(drv dialect.Driver) *Schema { return &Schema{drv: drv} }
 
 // Create creates all schema resources.
 func (s *Schema) Create(ctx context.Context, opts ...schema.MigrateOption) error {
-	return Create(ctx, s, Tables, opts...)
+	if err := Create(ctx, s, Tables, opts...); err != nil {
+		return err
+	}
+	return CreateViews(ctx, s, Views)
 }
 
 // Create creates all table resources using the given schema driver.
@@ -60,11 +63,41 @@ func Create(ctx context.Context, s *Schema, tables []*schema.Table, opts ...sche
 	return migrate.Create(ctx, tables...)
 }
 
+// View 迁移时创建的数据库视图
+type View struct {
+	Name string // 视图名称
+	SQL  string // 视图的查询语句
+}
+
+// CreateViews 创建或替换视图，视图依赖的表需要已经存在，
+// SQLite 不支持 CREATE OR REPLACE VIEW，先删除再创建
+func CreateViews(ctx context.Context, s *Schema, views []*View) error {
+	for _, v := range views {
+		b := &sql.Builder{}
+		b.SetDialect(s.drv.Dialect())
+		name := b.Quote(v.Name)
+		stmts := []string{"CREATE OR REPLACE VIEW " + name + " AS " + v.SQL}
+		if s.drv.Dialect() == dialect.SQLite {
+			stmts = []string{"DROP VIEW IF EXISTS " + name, "CREATE VIEW " + name + " AS " + v.SQL}
+		}
+		for _, stmt := range stmts {
+			if err := s.drv.Exec(ctx, stmt, []any{}, nil); err != nil {
+				return fmt.Errorf("ent/migrate: create view %s: %w", v.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 // WriteTo writes the schema changes to w instead of running them against the database.
 //
 //	if err := client.Schema.WriteTo(context.Background(), os.Stdout); err != nil {
 //		log.Fatal(err)
 //	}
 func (s *Schema) WriteTo(ctx context.Context, w io.Writer, opts ...schema.MigrateOption) error {
-	return Create(ctx, &Schema{drv: &schema.WriteDriver{Writer: w, Driver: s.drv}}, Tables, opts...)
+	ws := &Schema{drv: &schema.WriteDriver{Writer: w, Driver: s.drv}}
+	if err := Create(ctx, ws, Tables, opts...); err != nil {
+		return err
+	}
+	return CreateViews(ctx, ws, Views)
 }
@@ -8,10 +8,23 @@ import (
 )
 
 var (
+	// AccessColumns holds the columns for the "access" table.
+	AccessColumns = []*schema.Column{
+		{Name: "id", Type: field.TypeInt, Size: 0, Nullable: false, Unique: true, Increment: true, Comment: "权限ID"},
+		{Name: "access_name", Type: field.TypeString, Size: 50, Nullable: false, Unique: false, Default: "", Comment: "权限名称"},
+	}
+	// AccessTable holds the schema information for the "access" table.
+	AccessTable = &schema.Table{
+		Name:       "access",
+		Columns:    AccessColumns,
+		PrimaryKey: []*schema.Column{AccessColumns[0]},
+	}
+
 	// RoleColumns holds the columns for the "role" table.
 	RoleColumns = []*schema.Column{
-		{Name: "id", Type: field.TypeInt, Size: 0, Nullable: false, Unique: true, Increment: true},
-		{Name: "role_name", Type: field.TypeString, Size: 20, Nullable: false, Unique: false, Default: ""},
+		{Name: "id", Type: field.TypeInt, Size: 0, Nullable: false, Unique: true, Increment: true, Comment: "角色ID"},
+		{Name: "role_name", Type: field.TypeString, Size: 20, Nullable: false, Unique: false, Default: "", Comment: "角色名称"},
+		{Name: "access_id", Type: field.TypeInt, Size: 0, Nullable: false, Unique: false, Default: 0, Comment: "权限ID"},
 	}
 	// RoleTable holds the schema information for the "role" table.
 	RoleTable = &schema.Table{
@@ -22,10 +35,10 @@ var (
 
 	// UserColumns holds the columns for the "user" table.
 	UserColumns = []*schema.Column{
-		{Name: "id", Type: field.TypeInt, Size: 0, Nullable: false, Unique: true, Increment: true},
-		{Name: "username", Type: field.TypeString, Size: 255, Nullable: false, Unique: true, Default: "0"},
-		{Name: "nike_name", Type: field.TypeString, Size: 255, Nullable: false, Unique: false},
-		{Name: "role_id", Type: field.TypeInt, Size: 0, Nullable: false, Unique: false},
+		{Name: "id", Type: field.TypeInt, Size: 0, Nullable: false, Unique: true, Increment: true, Comment: "ID"},
+		{Name: "username", Type: field.TypeString, Size: 255, Nullable: false, Unique: true, Default: "", Comment: "用户账号"},
+		{Name: "nike_name", Type: field.TypeString, Size: 255, Nullable: false, Unique: false, Comment: "用户名称"},
+		{Name: "role_id", Type: field.TypeInt, Size: 0, Nullable: false, Unique: false, Comment: "角色ID"},
 	}
 	// UserTable holds the schema information for the "user" table.
 	UserTable = &schema.Table{
@@ -36,16 +49,26 @@ var (
 
 	// Tables holds all the tables in the schema.
 	Tables = []*schema.Table{
+		AccessTable,
 		RoleTable,
 		UserTable,
 	}
+
+	// Views 迁移时创建的视图，External 的视图由外部管理，不包含在内
+	Views = []*View{}
 )
 
 func init() {
+	AccessTable.Annotation = &entsql.Annotation{
+		Table:   "access",
+		Options: "COMMENT = '权限表'",
+	}
 	RoleTable.Annotation = &entsql.Annotation{
-		Table: "role",
+		Table:   "role",
+		Options: "COMMENT = '角色表'",
 	}
 	UserTable.Annotation = &entsql.Annotation{
-		Table: "user",
+		Table:   "user",
+		Options: "COMMENT = '用户表'",
 	}
 }
@@ -2,14 +2,18 @@
 package role
 
 import (
+	"context"
 	"entgo.io/ent/dialect/sql"
+	"fmt"
 	"github.com/go-kenka/esql"
+	"strings"
 )
 
 const (
 	TableName      = "role"
 	ColumnId       = "id"
 	ColumnRoleName = "role_name"
+	ColumnAccessId = "access_id"
 	// EdgeUserTableName user
 	EdgeUserTableName       = "user"
 	EdgeUserLinkField       = "id"
@@ -18,18 +22,20 @@ const (
 )
 
 var (
-	roleTable     = sql.Table(TableName).As("t1")
-	edgeUserTable = sql.Table(EdgeUserTableName).As("t2")
+	RoleTable     = sql.Table(TableName).As("t1")
+	EdgeUserTable = sql.Table(EdgeUserTableName).As("t2")
 )
 
 var Columns = []string{
 	ColumnId,
 	ColumnRoleName,
+	ColumnAccessId,
 }
 
 type RoleClient struct {
 	direct string
 	db     esql.Driver
+	cache  esql.Cache
 }
 
 type RoleData struct {
@@ -37,6 +43,16 @@ type RoleData struct {
 
 	Id       int    `db:"id"`        // 角色ID
 	RoleName string `db:"role_name"` // 角色名称
+	AccessId int    `db:"access_id"` // 权限ID
+}
+
+// String 返回数据的字符串表示，不包含敏感字段
+func (d *RoleData) String() string {
+	var fields []string
+	fields = append(fields, fmt.Sprintf("id=%v", d.Id))
+	fields = append(fields, fmt.Sprintf("role_name=%v", d.RoleName))
+	fields = append(fields, fmt.Sprintf("access_id=%v", d.AccessId))
+	return "RoleData(" + strings.Join(fields, ", ") + ")"
 }
 
 func (d *RoleData) HasUser() bool {
@@ -55,30 +71,56 @@ func NewRoleClient(db esql.Driver) *RoleClient {
 	}
 }
 
+// WithCache 设置查询缓存，通过 Query().Cache(ttl) 开启，
+// 当前客户端执行 Create/Update/Delete 时自动失效该表的缓存
+func (c *RoleClient) WithCache(cache esql.Cache) *RoleClient {
+	nc := *c
+	nc.cache = cache
+	return &nc
+}
+
 func (c *RoleClient) Query() *RoleQuery {
 	var cols []string
 	for _, column := range Columns {
-		cols = append(cols, roleTable.C(column))
+		cols = append(cols, RoleTable.C(column))
 	}
 	return &RoleQuery{
-		selector: sql.Dialect(c.direct).Select(cols...).From(roleTable),
+		selector: sql.Dialect(c.direct).Select(cols...).From(RoleTable),
 		db:       c.db,
+		cache:    c.cache,
 		with:     map[string]struct{}{},
 	}
 }
 
+// Get 按主键查询数据
+func (c *RoleClient) Get(ctx context.Context, id int) (*RoleData, error) {
+	return c.Query().Where(sql.EQ(ColumnId, id)).First(ctx)
+}
+
 func (c *RoleClient) Create() *RoleCreate {
+	var cols []string
+	for _, column := range Columns {
+		cols = append(cols, RoleTable.C(column))
+	}
 	return &RoleCreate{
-		builder: sql.Dialect(c.direct).Insert(TableName),
-		db:      c.db,
-		data:    &RoleData{},
+		selector: sql.Dialect(c.direct).Select(cols...).From(RoleTable),
+		builder:  sql.Dialect(c.direct).Insert(TableName),
+		db:       c.db,
+		cache:    c.cache,
+		data:     &RoleData{},
 	}
 }
 
 func (c *RoleClient) CreateBulk(data ...*RoleCreate) *RoleCreateBulk {
+	var cols []string
+	for _, column := range Columns {
+		cols = append(cols, RoleTable.C(column))
+	}
 	return &RoleCreateBulk{
-		db:   c.db,
-		data: data,
+		selector: sql.Dialect(c.direct).Select(cols...).From(RoleTable),
+		db:       c.db,
+		cache:    c.cache,
+		data:     data,
 	}
 }
 
@@ -86,6 +128,7 @@ func (c *RoleClient) Update() *RoleUpdate {
 	return &RoleUpdate{
 		builder: sql.Dialect(c.direct).Update(TableName),
 		db:      c.db,
+		cache:   c.cache,
 		data:    &RoleData{},
 	}
 }
@@ -93,7 +136,9 @@ func (c *RoleClient) Update() *RoleUpdate {
 func (c *RoleClient) UpdateOne(id int) *RoleUpdateOne {
 	return &RoleUpdateOne{
 		builder: sql.Dialect(c.direct).Update(TableName).Where(sql.EQ(ColumnId, id)),
+		key:     sql.EQ(ColumnId, id),
 		db:      c.db,
+		cache:   c.cache,
 		data:    &RoleData{},
 	}
 }
@@ -102,6 +147,7 @@ func (c *RoleClient) Delete() *RoleDelete {
 	return &RoleDelete{
 		builder: sql.Dialect(c.direct).Delete(TableName),
 		db:      c.db,
+		cache:   c.cache,
 	}
 }
 
@@ -109,5 +155,6 @@ func (c *RoleClient) DeleteOne(id int) *RoleDeleteOne {
 	return &RoleDeleteOne{
 		builder: sql.Dialect(c.direct).Delete(TableName).Where(sql.EQ(ColumnId, id)),
 		db:      c.db,
+		cache:   c.cache,
 	}
 }
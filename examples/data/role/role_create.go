@@ -3,23 +3,29 @@ package role
 
 import (
 	"context"
+	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
 	"github.com/go-kenka/esql"
-	"github.com/jmoiron/sqlx"
 )
 
 type RoleCreate struct {
 	builder  *sql.InsertBuilder
 	selector *sql.Selector
 	db       esql.Driver
+	cache    esql.Cache
 	data     *RoleData
+	id       any
 }
 
 func (c *RoleCreate) Set(column string, v any) *RoleCreate {
+	if column == ColumnId {
+		c.id = v
+	}
 	c.builder.Set(column, v)
 	return c
 }
 
+// Save 执行插入，并重新查询返回插入的数据
 func (c *RoleCreate) Save(ctx context.Context) (*RoleData, error) {
 	id, err := c.sqlSave(ctx)
 	if err != nil {
@@ -28,26 +34,58 @@ func (c *RoleCreate) Save(ctx context.Context) (*RoleData, error) {
 	return c.get(ctx, id)
 }
 
-func (c *RoleCreate) sqlSave(ctx context.Context) (int, error) {
-	query, args := c.builder.Query()
-	result, err := c.db.ExecContext(ctx, query, args...)
-	if err != nil {
-		return 0, err
-	}
+// Exec 执行插入，不重新查询插入的数据
+func (c *RoleCreate) Exec(ctx context.Context) error {
+	_, err := c.sqlSave(ctx)
+	return err
+}
 
-	id, _ := result.LastInsertId()
-	return int(id), nil
+// sqlSave 执行插入并返回主键：调用方指定了主键时直接使用，
+// 否则 MySQL 通过 LastInsertId 获取，Postgres、SQLite 通过 RETURNING 获取
+func (c *RoleCreate) sqlSave(ctx context.Context) (any, error) {
+	var id any
+	switch {
+	case c.id != nil:
+		query, args := c.sql()
+		if _, err := c.db.ExecContext(ctx, query, args...); err != nil {
+			return nil, err
+		}
+		id = c.id
+	case c.builder.Dialect() == dialect.MySQL:
+		query, args := c.sql()
+		result, err := c.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		id = int(lastID)
+	default:
+		c.builder.Returning(ColumnId)
+		query, args := c.sql()
+		var v int
+		if err := c.db.QueryRowxContext(ctx, query, args...).Scan(&v); err != nil {
+			return nil, err
+		}
+		id = v
+	}
+	if c.cache != nil {
+		c.cache.Invalidate(TableName)
+	}
+	return id, nil
 }
 
 func (c *RoleCreate) sql() (string, []any) {
 	return c.builder.Query()
 }
 
-func (c *RoleCreate) get(ctx context.Context, id int) (*RoleData, error) {
+func (c *RoleCreate) get(ctx context.Context, id any) (*RoleData, error) {
 	query, args := c.selector.Where(sql.EQ(ColumnId, id)).Query()
 	var data RoleData
 
-	err := c.db.GetContext(ctx, &data, query, args...)
+	err := esql.GetContext(ctx, c.db, &data, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -57,10 +95,12 @@ func (c *RoleCreate) get(ctx context.Context, id int) (*RoleData, error) {
 
 type RoleCreateBulk struct {
 	db       esql.Driver
+	cache    esql.Cache
 	selector *sql.Selector
 	data     []*RoleCreate
 }
 
+// Save 执行插入，并重新查询返回插入的数据
 func (cb RoleCreateBulk) Save(ctx context.Context) ([]*RoleData, error) {
 	ids, err := cb.sqlSave(ctx)
 	if err != nil {
@@ -68,26 +108,22 @@ func (cb RoleCreateBulk) Save(ctx context.Context) ([]*RoleData, error) {
 	}
 	return cb.find(ctx, ids)
 }
+
+// Exec 执行插入，不重新查询插入的数据
+func (cb RoleCreateBulk) Exec(ctx context.Context) error {
+	_, err := cb.sqlSave(ctx)
+	return err
+}
+
+// sqlSave 逐条插入，每条数据设置的字段可能不同，因此不复用预编译语句
 func (cb RoleCreateBulk) sqlSave(ctx context.Context) ([]any, error) {
 	var ids []any
-	var stmt *sqlx.Stmt
-	var err error
-	for i, d := range cb.data {
-		query, args := d.sql()
-		if i == 0 {
-			stmt, err = cb.db.Preparex(query)
-			if err != nil {
-				return nil, err
-			}
-		}
-		if stmt != nil {
-			result, err := stmt.ExecContext(ctx, args...)
-			if err != nil {
-				return nil, err
-			}
-			id, _ := result.LastInsertId()
-			ids = append(ids, id)
+	for _, d := range cb.data {
+		id, err := d.sqlSave(ctx)
+		if err != nil {
+			return nil, err
 		}
+		ids = append(ids, id)
 	}
 	return ids, nil
 }
@@ -95,7 +131,7 @@ func (cb RoleCreateBulk) sqlSave(ctx context.Context) ([]any, error) {
 func (cb RoleCreateBulk) find(ctx context.Context, ids []any) ([]*RoleData, error) {
 	query, args := cb.selector.Where(sql.In(ColumnId, ids...)).Query()
 	var data []*RoleData
-	err := cb.db.SelectContext(ctx, data, query, args...)
+	err := esql.SelectContext(ctx, cb.db, &data, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -10,6 +10,7 @@ import (
 type RoleDelete struct {
 	builder *sql.DeleteBuilder
 	db      esql.Driver
+	cache   esql.Cache
 }
 
 func (d *RoleDelete) Where(p *sql.Predicate) *RoleDelete {
@@ -27,6 +28,9 @@ func (d *RoleDelete) sqlSave(ctx context.Context) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	if d.cache != nil {
+		d.cache.Invalidate(TableName)
+	}
 	aff, _ := result.RowsAffected()
 	return int(aff), nil
 }
@@ -34,6 +38,7 @@ func (d *RoleDelete) sqlSave(ctx context.Context) (int, error) {
 type RoleDeleteOne struct {
 	builder *sql.DeleteBuilder
 	db      esql.Driver
+	cache   esql.Cache
 }
 
 func (d *RoleDeleteOne) Save(ctx context.Context) error {
@@ -46,5 +51,8 @@ func (d *RoleDeleteOne) sqlSave(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if d.cache != nil {
+		d.cache.Invalidate(TableName)
+	}
 	return nil
 }
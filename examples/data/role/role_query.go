@@ -5,12 +5,15 @@ import (
 	"context"
 	"entgo.io/ent/dialect/sql"
 	"github.com/go-kenka/esql"
+	"time"
 )
 
 type RoleQuery struct {
 	selector *sql.Selector
 	db       esql.Driver
 	with     map[string]struct{}
+	cache    esql.Cache
+	ttl      time.Duration
 }
 
 // Select changes the columns selection of the SELECT statement.
@@ -274,23 +277,26 @@ func (q *RoleQuery) C(column string) string {
 }
 
 func (q *RoleQuery) Clone() *RoleQuery {
-
 	with := make(map[string]struct{})
 	for k, v := range q.with {
 		with[k] = v
 	}
-
 	return &RoleQuery{
 		selector: q.selector.Clone(),
 		db:       q.db,
 		with:     with,
+		cache:    q.cache,
+		ttl:      q.ttl,
 	}
 }
 
 func (q *RoleQuery) First(ctx context.Context) (*RoleData, error) {
 	query, args := q.Limit(1).Query()
+	if v, ok := q.cacheGet(query, args); ok {
+		return v.(*RoleData), nil
+	}
 	var data RoleData
-	err := q.db.GetContext(ctx, &data, query, args...)
+	err := esql.GetContext(ctx, q.db, &data, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -300,6 +306,7 @@ func (q *RoleQuery) First(ctx context.Context) (*RoleData, error) {
 		return nil, err
 	}
 
+	q.cacheSet(query, args, &data)
 	return &data, nil
 }
 
@@ -307,15 +314,11 @@ func (q *RoleQuery) FirstID(ctx context.Context) (int, error) {
 	query, args := q.Select(ColumnId).Limit(1).Query()
 	var id int
 	err := q.db.QueryRowxContext(ctx, query, args...).Scan(&id)
-	if err != nil {
-		return 0, err
-	}
-
-	return id, nil
+	return id, err
 }
 
 func (q *RoleQuery) IDs(ctx context.Context) ([]int, error) {
-	query, args := q.Select(ColumnId).Limit(1).Query()
+	query, args := q.Select(ColumnId).Query()
 	rows, err := q.db.QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -336,7 +339,7 @@ func (q *RoleQuery) IDs(ctx context.Context) ([]int, error) {
 
 func (q *RoleQuery) ScanX(ctx context.Context, dist any) error {
 	query, args := q.Query()
-	err := q.db.SelectContext(ctx, &dist, query, args...)
+	err := esql.SelectContext(ctx, q.db, dist, query, args...)
 	if err != nil {
 		return err
 	}
@@ -346,8 +349,11 @@ func (q *RoleQuery) ScanX(ctx context.Context, dist any) error {
 
 func (q *RoleQuery) AllX(ctx context.Context) ([]*RoleData, error) {
 	query, args := q.Query()
+	if v, ok := q.cacheGet(query, args); ok {
+		return v.([]*RoleData), nil
+	}
 	var data []*RoleData
-	err := q.db.SelectContext(ctx, &data, query, args...)
+	err := esql.SelectContext(ctx, q.db, &data, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -357,6 +363,7 @@ func (q *RoleQuery) AllX(ctx context.Context) ([]*RoleData, error) {
 		return nil, err
 	}
 
+	q.cacheSet(query, args, data)
 	return data, nil
 }
 
@@ -389,53 +396,76 @@ func (q *RoleQuery) WithUserList() *RoleQuery {
 
 func (q *RoleQuery) UserQuery() *sql.Selector {
 	var cols []string
-	cols = append(cols, edgeUserTable.C(EdgeUserRefField))
-	cols = append(cols, edgeUserTable.C(EdgeUserDisplayNikeName))
+	cols = append(cols, EdgeUserTable.C(EdgeUserRefField))
+	cols = append(cols, EdgeUserTable.C(EdgeUserDisplayNikeName))
+
+	s := sql.Dialect(q.db.DriverName()).Select(cols...).From(EdgeUserTable)
+	return s
+}
+
+// Cache 开启查询缓存，First、AllX 的结果缓存 ttl 时长，需要客户端通过 WithCache 设置了缓存
+func (q *RoleQuery) Cache(ttl time.Duration) *RoleQuery {
+	q.ttl = ttl
+	return q
+}
 
-	return sql.Dialect(q.db.DriverName()).Select(cols...).From(edgeUserTable)
+// cacheGet 读取缓存，返回缓存数据的深拷贝
+func (q *RoleQuery) cacheGet(query string, args []any) (any, bool) {
+	if q.cache == nil || q.ttl <= 0 {
+		return nil, false
+	}
+	v, ok := q.cache.Get(q.cacheKey(query, args))
+	if !ok {
+		return nil, false
+	}
+	return esql.DeepCopy(v), true
+}
+
+// cacheSet 写入结果的深拷贝，调用方之后的修改不会影响缓存
+func (q *RoleQuery) cacheSet(query string, args []any, v any) {
+	if q.cache == nil || q.ttl <= 0 {
+		return
+	}
+	q.cache.Set(q.cacheKey(query, args), esql.DeepCopy(v), q.ttl, q.cacheTables()...)
+}
+
+func (q *RoleQuery) cacheKey(query string, args []any) string {
+	var with []string
+	for k := range q.with {
+		with = append(with, k)
+	}
+	return esql.CacheKey(query, args, with...)
+}
+
+// cacheTables 返回查询结果依赖的表，任意一张表发生写入时缓存失效
+func (q *RoleQuery) cacheTables() []string {
+	tables := []string{TableName}
+	if _, ok := q.with["user"]; ok {
+		tables = append(tables, EdgeUserTableName)
+	}
+	return tables
 }
 
 func (q *RoleQuery) queryWith(ctx context.Context, data []*RoleData) error {
 
 	if _, ok := q.with["user"]; ok {
-		var ids []int
+		var ids []any
 		for _, datum := range data {
 			ids = append(ids, datum.Id)
 		}
 
-		query, args := q.UserQuery().Where(sql.InInts(EdgeUserRefField, ids...)).OrderBy(sql.Desc(EdgeUserRefField)).Query()
+		selector := q.UserQuery().Where(sql.In(EdgeUserRefField, ids...))
+		query, args := selector.OrderBy(sql.Desc(EdgeUserRefField)).Query()
 		var userData []*RoleEdgeUserData
-		err := q.db.SelectContext(ctx, &userData, query, args...)
+		err := esql.SelectContext(ctx, q.db, &userData, query, args...)
 		if err != nil {
 			return err
 		}
 
 		userMap := make(map[int][]*RoleEdgeUserData)
 
-		var withData []*RoleEdgeUserData
-		var lastId int
-		for i, a := range userData {
-			if i == 0 {
-				withData = append(withData, a)
-				lastId = a.RoleId
-				continue
-			}
-
-			if a.RoleId == lastId {
-				withData = append(withData, a)
-				continue
-			}
-
-			// 下一条
-			{
-				// 添加数据到map中
-				userMap[lastId] = append(userMap[lastId], withData...)
-				// 更新lastId
-				lastId = a.RoleId
-				// 清空data，并传入当前值
-				withData = nil
-				withData = append(withData, a)
-			}
+		for _, a := range userData {
+			userMap[a.RoleId] = append(userMap[a.RoleId], a)
 		}
 
 		for _, d := range data {
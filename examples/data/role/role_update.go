@@ -3,13 +3,16 @@ package role
 
 import (
 	"context"
+	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
 	"github.com/go-kenka/esql"
 )
 
 type RoleUpdate struct {
 	builder *sql.UpdateBuilder
+	where   []*sql.Predicate // MySQL 更新后按条件查询更新前的主键
 	db      esql.Driver
+	cache   esql.Cache
 	data    *RoleData
 }
 
@@ -27,6 +30,7 @@ func (u *RoleUpdate) Add(column string, v any) *RoleUpdate {
 }
 
 func (u *RoleUpdate) Where(p *sql.Predicate) *RoleUpdate {
+	u.where = append(u.where, p)
 	u.builder.Where(p)
 	return u
 }
@@ -37,24 +41,66 @@ func (u *RoleUpdate) Save(ctx context.Context) ([]*RoleData, error) {
 }
 
 func (u *RoleUpdate) sqlSave(ctx context.Context) ([]*RoleData, error) {
+	if u.builder.Dialect() == dialect.MySQL {
+		return u.mysqlSave(ctx)
+	}
 	query, args := u.builder.Query()
-	stmt, err := u.db.Preparex(query)
+	stmt, err := u.db.PreparexContext(ctx, query)
 	if err != nil {
-
 		return nil, err
 	}
+	defer stmt.Close()
 	var data []*RoleData
 
-	err = stmt.SelectContext(ctx, data, args...)
+	err = stmt.SelectContext(ctx, &data, args...)
 	if err != nil {
 		return nil, err
 	}
+	if u.cache != nil {
+		u.cache.Invalidate(TableName)
+	}
+	return data, nil
+}
+
+// mysqlSave MySQL 不支持 RETURNING，先按条件查询需要更新的数据，执行更新后按主键重新查询
+func (u *RoleUpdate) mysqlSave(ctx context.Context) ([]*RoleData, error) {
+	selector := sql.Dialect(dialect.MySQL).Select(Columns...).From(sql.Table(TableName))
+	if len(u.where) > 0 {
+		selector.Where(sql.And(u.where...))
+	}
+	query, args := selector.Query()
+	var rows []*RoleData
+	if err := esql.SelectContext(ctx, u.db, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	query, args = u.builder.Query()
+	if _, err := u.db.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+	if u.cache != nil {
+		u.cache.Invalidate(TableName)
+	}
+	ids := make([]any, 0, len(rows))
+	for _, d := range rows {
+		ids = append(ids, d.Id)
+	}
+	query, args = sql.Dialect(dialect.MySQL).Select(Columns...).From(sql.Table(TableName)).Where(sql.In(ColumnId, ids...)).Query()
+	var data []*RoleData
+	if err := esql.SelectContext(ctx, u.db, &data, query, args...); err != nil {
+		return nil, err
+	}
 	return data, nil
 }
 
 type RoleUpdateOne struct {
 	builder *sql.UpdateBuilder
+	key     *sql.Predicate // MySQL 更新后按主键重新查询
 	db      esql.Driver
+	cache   esql.Cache
 	data    *RoleData
 }
 
@@ -77,17 +123,41 @@ func (u *RoleUpdateOne) Save(ctx context.Context) (*RoleData, error) {
 }
 
 func (u *RoleUpdateOne) sqlSave(ctx context.Context) (*RoleData, error) {
+	if u.builder.Dialect() == dialect.MySQL {
+		return u.mysqlSave(ctx)
+	}
 	query, args := u.builder.Query()
-	stmt, err := u.db.Preparex(query)
+	stmt, err := u.db.PreparexContext(ctx, query)
 	if err != nil {
-
 		return nil, err
 	}
+	defer stmt.Close()
 	var data RoleData
 
-	err = stmt.SelectContext(ctx, &data, args...)
+	err = stmt.GetContext(ctx, &data, args...)
 	if err != nil {
 		return nil, err
 	}
+	if u.cache != nil {
+		u.cache.Invalidate(TableName)
+	}
+	return &data, nil
+}
+
+// mysqlSave MySQL 不支持 RETURNING，执行更新后按主键重新查询
+func (u *RoleUpdateOne) mysqlSave(ctx context.Context) (*RoleData, error) {
+	query, args := u.builder.Query()
+	if _, err := u.db.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+	if u.cache != nil {
+		u.cache.Invalidate(TableName)
+	}
+
+	query, args = sql.Dialect(dialect.MySQL).Select(Columns...).From(sql.Table(TableName)).Where(u.key).Query()
+	var data RoleData
+	if err := esql.GetContext(ctx, u.db, &data, query, args...); err != nil {
+		return nil, err
+	}
 	return &data, nil
 }
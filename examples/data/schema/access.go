@@ -0,0 +1,28 @@
+package schema
+
+import (
+	. "github.com/go-kenka/esql/dsl"
+)
+
+var _ = Table("access",
+	Desc("权限表"),
+	Fields(
+		Field("id",
+			Tag("db:\"id\""),
+			TypeInfo(TypeInt),
+			Unique(true),
+			Nillable(false),
+			Default(0),
+			Comment("权限ID"),
+		),
+		Field("access_name",
+			Tag("db:\"access_name\""),
+			TypeInfo(TypeString),
+			Size(50),
+			Unique(false),
+			Nillable(false),
+			Default(""),
+			Comment("权限名称"),
+		),
+	),
+)
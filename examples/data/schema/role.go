@@ -24,6 +24,14 @@ var _ = Table("role",
 			Default(""),
 			Comment("角色名称"),
 		),
+		Field("access_id",
+			Tag("db:\"access_id\""),
+			TypeInfo(TypeInt),
+			Unique(false),
+			Nillable(false),
+			Default(0),
+			Comment("权限ID"),
+		),
 	),
 	Edges(
 		Edge("user",
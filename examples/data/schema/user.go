@@ -20,7 +20,7 @@ var _ = Table("user",
 			TypeInfo(TypeString),
 			Unique(true),
 			Nillable(false),
-			Default(0),
+			Default(""),
 			Comment("用户账号"),
 		),
 		Field("nike_name",
@@ -67,4 +67,7 @@ var _ = Table("user",
 			),
 		),
 	),
+	Queries(
+		Query("ActiveUsersByRole", "SELECT * FROM user WHERE role_id = :role_id"),
+	),
 )
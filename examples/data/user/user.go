@@ -2,8 +2,11 @@
 package user
 
 import (
+	"context"
 	"entgo.io/ent/dialect/sql"
+	"fmt"
 	"github.com/go-kenka/esql"
+	"strings"
 )
 
 const (
@@ -17,17 +20,17 @@ const (
 	EdgeRoleLinkField       = "role_id"
 	EdgeRoleRefField        = "id"
 	EdgeRoleDisplayRoleName = "role_name"
-	// RoleEdgeAccessTableName access
-	RoleEdgeAccessTableName         = "access"
-	RoleEdgeAccessLinkField         = "access_id"
-	RoleEdgeAccessRefField          = "id"
-	RoleEdgeAccessDisplayAccessName = "access_name"
+	// EdgeRoleAccessTableName role__access
+	EdgeRoleAccessTableName         = "access"
+	EdgeRoleAccessLinkField         = "access_id"
+	EdgeRoleAccessRefField          = "id"
+	EdgeRoleAccessDisplayAccessName = "access_name"
 )
 
 var (
-	userTable           = sql.Table(TableName).As("t1")
-	edgeRoleTable       = sql.Table(EdgeRoleTableName).As("t2")
-	roleEdgeAccessTable = sql.Table(RoleEdgeAccessTableName)
+	UserTable           = sql.Table(TableName).As("t1")
+	EdgeRoleTable       = sql.Table(EdgeRoleTableName).As("t2")
+	EdgeRoleAccessTable = sql.Table(EdgeRoleAccessTableName).As("t3")
 )
 
 var Columns = []string{
@@ -37,9 +40,16 @@ var Columns = []string{
 	ColumnRoleId,
 }
 
+// Required 创建时必须赋值的字段
+var Required = []string{
+	ColumnNikeName,
+	ColumnRoleId,
+}
+
 type UserClient struct {
 	direct string
 	db     esql.Driver
+	cache  esql.Cache
 }
 
 type UserData struct {
@@ -51,22 +61,34 @@ type UserData struct {
 	RoleId   int    `db:"role_id"`   // 角色ID
 }
 
+// String 返回数据的字符串表示，不包含敏感字段
+func (d *UserData) String() string {
+	var fields []string
+	fields = append(fields, fmt.Sprintf("id=%v", d.Id))
+	fields = append(fields, fmt.Sprintf("username=%v", d.Username))
+	fields = append(fields, fmt.Sprintf("nike_name=%v", d.NikeName))
+	fields = append(fields, fmt.Sprintf("role_id=%v", d.RoleId))
+	return "UserData(" + strings.Join(fields, ", ") + ")"
+}
+
 func (d *UserData) HasRole() bool {
-	return d.UserEdgeRoleData != nil
+	// 左连接没有匹配的数据时 Ref 为NULL
+	return d.UserEdgeRoleData != nil && d.UserEdgeRoleData.Id != nil
 }
 
 type UserEdgeRoleData struct {
-	*RoleEdgeAccessData
-	Id       int    `db:"id"`        // id
-	RoleName string `db:"role_name"` //
+	*UserEdgeRoleAccessData
+	Id       *int    `db:"role__id"`        // id
+	RoleName *string `db:"role__role_name"` //
 }
 
 func (d *UserEdgeRoleData) HasAccess() bool {
-	return d.RoleEdgeAccessData != nil
+	return d.UserEdgeRoleAccessData != nil && d.UserEdgeRoleAccessData.Id != nil
 }
 
-type RoleEdgeAccessData struct {
-	AccessName string `db:"access_name"` //
+type UserEdgeRoleAccessData struct {
+	Id         *int    `db:"role__access__id"`          // id
+	AccessName *string `db:"role__access__access_name"` //
 }
 
 func NewUserClient(db esql.Driver) *UserClient {
@@ -76,30 +98,61 @@ func NewUserClient(db esql.Driver) *UserClient {
 	}
 }
 
+// WithCache 设置查询缓存，通过 Query().Cache(ttl) 开启，
+// 当前客户端执行 Create/Update/Delete 时自动失效该表的缓存
+func (c *UserClient) WithCache(cache esql.Cache) *UserClient {
+	nc := *c
+	nc.cache = cache
+	return &nc
+}
+
 func (c *UserClient) Query() *UserQuery {
 	var cols []string
 	for _, column := range Columns {
-		cols = append(cols, userTable.C(column))
+		cols = append(cols, UserTable.C(column))
 	}
 	return &UserQuery{
-		selector: sql.Dialect(c.direct).Select(cols...).From(userTable),
+		selector: sql.Dialect(c.direct).Select(cols...).From(UserTable),
 		db:       c.db,
+		cache:    c.cache,
 		with:     map[string]struct{}{},
 	}
 }
 
+// Get 按主键查询数据
+func (c *UserClient) Get(ctx context.Context, id int) (*UserData, error) {
+	return c.Query().Where(sql.EQ(ColumnId, id)).First(ctx)
+}
+
 func (c *UserClient) Create() *UserCreate {
-	return &UserCreate{
-		builder: sql.Dialect(c.direct).Insert(TableName),
-		db:      c.db,
-		data:    &UserData{},
+	var cols []string
+	for _, column := range Columns {
+		cols = append(cols, UserTable.C(column))
+	}
+	create := &UserCreate{
+		selector: sql.Dialect(c.direct).Select(cols...).From(UserTable),
+		builder:  sql.Dialect(c.direct).Insert(TableName),
+		db:       c.db,
+		cache:    c.cache,
+		data:     &UserData{},
 	}
+	// 必填字段在 Set 时清除错误
+	for _, column := range Required {
+		create.errs.Set(column, esql.ErrRequired)
+	}
+	return create
 }
 
 func (c *UserClient) CreateBulk(data ...*UserCreate) *UserCreateBulk {
+	var cols []string
+	for _, column := range Columns {
+		cols = append(cols, UserTable.C(column))
+	}
 	return &UserCreateBulk{
-		db:   c.db,
-		data: data,
+		selector: sql.Dialect(c.direct).Select(cols...).From(UserTable),
+		db:       c.db,
+		cache:    c.cache,
+		data:     data,
 	}
 }
 
@@ -107,6 +160,7 @@ func (c *UserClient) Update() *UserUpdate {
 	return &UserUpdate{
 		builder: sql.Dialect(c.direct).Update(TableName),
 		db:      c.db,
+		cache:   c.cache,
 		data:    &UserData{},
 	}
 }
@@ -114,7 +168,9 @@ func (c *UserClient) Update() *UserUpdate {
 func (c *UserClient) UpdateOne(id int) *UserUpdateOne {
 	return &UserUpdateOne{
 		builder: sql.Dialect(c.direct).Update(TableName).Where(sql.EQ(ColumnId, id)),
+		key:     sql.EQ(ColumnId, id),
 		db:      c.db,
+		cache:   c.cache,
 		data:    &UserData{},
 	}
 }
@@ -123,6 +179,7 @@ func (c *UserClient) Delete() *UserDelete {
 	return &UserDelete{
 		builder: sql.Dialect(c.direct).Delete(TableName),
 		db:      c.db,
+		cache:   c.cache,
 	}
 }
 
@@ -130,5 +187,6 @@ func (c *UserClient) DeleteOne(id int) *UserDeleteOne {
 	return &UserDeleteOne{
 		builder: sql.Dialect(c.direct).Delete(TableName).Where(sql.EQ(ColumnId, id)),
 		db:      c.db,
+		cache:   c.cache,
 	}
 }
@@ -3,23 +3,31 @@ package user
 
 import (
 	"context"
+	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
 	"github.com/go-kenka/esql"
-	"github.com/jmoiron/sqlx"
 )
 
 type UserCreate struct {
 	builder  *sql.InsertBuilder
 	selector *sql.Selector
 	db       esql.Driver
+	cache    esql.Cache
 	data     *UserData
+	id       any
+	errs     esql.FieldErrors // 字段的校验错误
 }
 
 func (c *UserCreate) Set(column string, v any) *UserCreate {
+	if column == ColumnId {
+		c.id = v
+	}
+	c.errs.Set(column, nil)
 	c.builder.Set(column, v)
 	return c
 }
 
+// Save 执行插入，并重新查询返回插入的数据
 func (c *UserCreate) Save(ctx context.Context) (*UserData, error) {
 	id, err := c.sqlSave(ctx)
 	if err != nil {
@@ -28,26 +36,61 @@ func (c *UserCreate) Save(ctx context.Context) (*UserData, error) {
 	return c.get(ctx, id)
 }
 
-func (c *UserCreate) sqlSave(ctx context.Context) (int, error) {
-	query, args := c.builder.Query()
-	result, err := c.db.ExecContext(ctx, query, args...)
-	if err != nil {
-		return 0, err
-	}
+// Exec 执行插入，不重新查询插入的数据
+func (c *UserCreate) Exec(ctx context.Context) error {
+	_, err := c.sqlSave(ctx)
+	return err
+}
 
-	id, _ := result.LastInsertId()
-	return int(id), nil
+// sqlSave 执行插入并返回主键：调用方指定了主键时直接使用，
+// 否则 MySQL 通过 LastInsertId 获取，Postgres、SQLite 通过 RETURNING 获取
+func (c *UserCreate) sqlSave(ctx context.Context) (any, error) {
+	if err := c.errs.Err(TableName, Columns); err != nil {
+		return nil, err
+	}
+	var id any
+	switch {
+	case c.id != nil:
+		query, args := c.sql()
+		if _, err := c.db.ExecContext(ctx, query, args...); err != nil {
+			return nil, err
+		}
+		id = c.id
+	case c.builder.Dialect() == dialect.MySQL:
+		query, args := c.sql()
+		result, err := c.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		id = int(lastID)
+	default:
+		c.builder.Returning(ColumnId)
+		query, args := c.sql()
+		var v int
+		if err := c.db.QueryRowxContext(ctx, query, args...).Scan(&v); err != nil {
+			return nil, err
+		}
+		id = v
+	}
+	if c.cache != nil {
+		c.cache.Invalidate(TableName)
+	}
+	return id, nil
 }
 
 func (c *UserCreate) sql() (string, []any) {
 	return c.builder.Query()
 }
 
-func (c *UserCreate) get(ctx context.Context, id int) (*UserData, error) {
+func (c *UserCreate) get(ctx context.Context, id any) (*UserData, error) {
 	query, args := c.selector.Where(sql.EQ(ColumnId, id)).Query()
 	var data UserData
 
-	err := c.db.GetContext(ctx, &data, query, args...)
+	err := esql.GetContext(ctx, c.db, &data, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -57,10 +100,12 @@ func (c *UserCreate) get(ctx context.Context, id int) (*UserData, error) {
 
 type UserCreateBulk struct {
 	db       esql.Driver
+	cache    esql.Cache
 	selector *sql.Selector
 	data     []*UserCreate
 }
 
+// Save 执行插入，并重新查询返回插入的数据
 func (cb UserCreateBulk) Save(ctx context.Context) ([]*UserData, error) {
 	ids, err := cb.sqlSave(ctx)
 	if err != nil {
@@ -68,26 +113,22 @@ func (cb UserCreateBulk) Save(ctx context.Context) ([]*UserData, error) {
 	}
 	return cb.find(ctx, ids)
 }
+
+// Exec 执行插入，不重新查询插入的数据
+func (cb UserCreateBulk) Exec(ctx context.Context) error {
+	_, err := cb.sqlSave(ctx)
+	return err
+}
+
+// sqlSave 逐条插入，每条数据设置的字段可能不同，因此不复用预编译语句
 func (cb UserCreateBulk) sqlSave(ctx context.Context) ([]any, error) {
 	var ids []any
-	var stmt *sqlx.Stmt
-	var err error
-	for i, d := range cb.data {
-		query, args := d.sql()
-		if i == 0 {
-			stmt, err = cb.db.Preparex(query)
-			if err != nil {
-				return nil, err
-			}
-		}
-		if stmt != nil {
-			result, err := stmt.ExecContext(ctx, args...)
-			if err != nil {
-				return nil, err
-			}
-			id, _ := result.LastInsertId()
-			ids = append(ids, id)
+	for _, d := range cb.data {
+		id, err := d.sqlSave(ctx)
+		if err != nil {
+			return nil, err
 		}
+		ids = append(ids, id)
 	}
 	return ids, nil
 }
@@ -95,7 +136,7 @@ func (cb UserCreateBulk) sqlSave(ctx context.Context) ([]any, error) {
 func (cb UserCreateBulk) find(ctx context.Context, ids []any) ([]*UserData, error) {
 	query, args := cb.selector.Where(sql.In(ColumnId, ids...)).Query()
 	var data []*UserData
-	err := cb.db.SelectContext(ctx, data, query, args...)
+	err := esql.SelectContext(ctx, cb.db, &data, query, args...)
 	if err != nil {
 		return nil, err
 	}
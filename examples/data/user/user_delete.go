@@ -10,6 +10,7 @@ import (
 type UserDelete struct {
 	builder *sql.DeleteBuilder
 	db      esql.Driver
+	cache   esql.Cache
 }
 
 func (d *UserDelete) Where(p *sql.Predicate) *UserDelete {
@@ -27,6 +28,9 @@ func (d *UserDelete) sqlSave(ctx context.Context) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	if d.cache != nil {
+		d.cache.Invalidate(TableName)
+	}
 	aff, _ := result.RowsAffected()
 	return int(aff), nil
 }
@@ -34,6 +38,7 @@ func (d *UserDelete) sqlSave(ctx context.Context) (int, error) {
 type UserDeleteOne struct {
 	builder *sql.DeleteBuilder
 	db      esql.Driver
+	cache   esql.Cache
 }
 
 func (d *UserDeleteOne) Save(ctx context.Context) error {
@@ -46,5 +51,8 @@ func (d *UserDeleteOne) sqlSave(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if d.cache != nil {
+		d.cache.Invalidate(TableName)
+	}
 	return nil
 }
@@ -0,0 +1,26 @@
+// Code generated by esql, DO NOT EDIT.
+package user
+
+import (
+	"context"
+	"github.com/go-kenka/esql"
+)
+
+// activeUsersByRoleQuery 命名查询 ActiveUsersByRole 的语句
+const activeUsersByRoleQuery = "SELECT * FROM user WHERE role_id = ?"
+
+// ActiveUsersByRoleParams 命名查询 ActiveUsersByRole 的参数
+type ActiveUsersByRoleParams struct {
+	RoleId int // 角色ID
+}
+
+// ActiveUsersByRole 执行命名查询 ActiveUsersByRole
+func (c *UserClient) ActiveUsersByRole(ctx context.Context, params ActiveUsersByRoleParams) ([]*UserData, error) {
+	query := c.db.Rebind(activeUsersByRoleQuery)
+	var data []*UserData
+	err := esql.SelectContext(ctx, c.db, &data, query, params.RoleId)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -5,12 +5,15 @@ import (
 	"context"
 	"entgo.io/ent/dialect/sql"
 	"github.com/go-kenka/esql"
+	"time"
 )
 
 type UserQuery struct {
 	selector *sql.Selector
 	db       esql.Driver
 	with     map[string]struct{}
+	cache    esql.Cache
+	ttl      time.Duration
 }
 
 // Select changes the columns selection of the SELECT statement.
@@ -269,10 +272,31 @@ func (q *UserQuery) Query() (string, []any) {
 	return q.selector.Query()
 }
 
+func (q *UserQuery) C(column string) string {
+	return q.selector.C(column)
+}
+
+func (q *UserQuery) Clone() *UserQuery {
+	with := make(map[string]struct{})
+	for k, v := range q.with {
+		with[k] = v
+	}
+	return &UserQuery{
+		selector: q.selector.Clone(),
+		db:       q.db,
+		with:     with,
+		cache:    q.cache,
+		ttl:      q.ttl,
+	}
+}
+
 func (q *UserQuery) First(ctx context.Context) (*UserData, error) {
 	query, args := q.Limit(1).Query()
+	if v, ok := q.cacheGet(query, args); ok {
+		return v.(*UserData), nil
+	}
 	var data UserData
-	err := q.db.GetContext(ctx, &data, query, args...)
+	err := esql.GetContext(ctx, q.db, &data, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -282,6 +306,7 @@ func (q *UserQuery) First(ctx context.Context) (*UserData, error) {
 		return nil, err
 	}
 
+	q.cacheSet(query, args, &data)
 	return &data, nil
 }
 
@@ -289,15 +314,11 @@ func (q *UserQuery) FirstID(ctx context.Context) (int, error) {
 	query, args := q.Select(ColumnId).Limit(1).Query()
 	var id int
 	err := q.db.QueryRowxContext(ctx, query, args...).Scan(&id)
-	if err != nil {
-		return 0, err
-	}
-
-	return id, nil
+	return id, err
 }
 
 func (q *UserQuery) IDs(ctx context.Context) ([]int, error) {
-	query, args := q.Select(ColumnId).Limit(1).Query()
+	query, args := q.Select(ColumnId).Query()
 	rows, err := q.db.QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -318,7 +339,7 @@ func (q *UserQuery) IDs(ctx context.Context) ([]int, error) {
 
 func (q *UserQuery) ScanX(ctx context.Context, dist any) error {
 	query, args := q.Query()
-	err := q.db.SelectContext(ctx, &dist, query, args...)
+	err := esql.SelectContext(ctx, q.db, dist, query, args...)
 	if err != nil {
 		return err
 	}
@@ -328,8 +349,11 @@ func (q *UserQuery) ScanX(ctx context.Context, dist any) error {
 
 func (q *UserQuery) AllX(ctx context.Context) ([]*UserData, error) {
 	query, args := q.Query()
+	if v, ok := q.cacheGet(query, args); ok {
+		return v.([]*UserData), nil
+	}
 	var data []*UserData
-	err := q.db.SelectContext(ctx, &data, query, args...)
+	err := esql.SelectContext(ctx, q.db, &data, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -339,6 +363,7 @@ func (q *UserQuery) AllX(ctx context.Context) ([]*UserData, error) {
 		return nil, err
 	}
 
+	q.cacheSet(query, args, data)
 	return data, nil
 }
 
@@ -365,25 +390,71 @@ func (q *UserQuery) ExistX(ctx context.Context) (bool, error) {
 }
 
 func (q *UserQuery) WithRole() *UserQuery {
-	// 添加Display字段
-	q.AppendSelect(edgeRoleTable.C(EdgeRoleDisplayRoleName))
+	q.with["role"] = struct{}{}
+	// 添加Ref以及Display字段
+	q.AppendSelect(sql.As(EdgeRoleTable.C(EdgeRoleRefField), "role__id"))
+	q.AppendSelect(sql.As(EdgeRoleTable.C(EdgeRoleDisplayRoleName), "role__role_name"))
 	// 添加关系（左连接）
-	q.LeftJoin(edgeRoleTable).
+	q.LeftJoin(EdgeRoleTable).
 		On(
 			q.C(EdgeRoleLinkField),
-			edgeRoleTable.C(EdgeRoleRefField),
+			EdgeRoleTable.C(EdgeRoleRefField),
 		)
-	// 添加Display字段
-	q.AppendSelect(roleEdgeAccessTable.C(RoleEdgeAccessDisplayAccessName))
+	// 添加Ref以及Display字段
+	q.AppendSelect(sql.As(EdgeRoleAccessTable.C(EdgeRoleAccessRefField), "role__access__id"))
+	q.AppendSelect(sql.As(EdgeRoleAccessTable.C(EdgeRoleAccessDisplayAccessName), "role__access__access_name"))
 	// 添加关系（左连接）
-	q.LeftJoin(roleEdgeAccessTable).
+	q.LeftJoin(EdgeRoleAccessTable).
 		On(
-			edgeRoleTable.C(RoleEdgeAccessLinkField),
-			roleEdgeAccessTable.C(RoleEdgeAccessRefField),
+			EdgeRoleTable.C(EdgeRoleAccessLinkField),
+			EdgeRoleAccessTable.C(EdgeRoleAccessRefField),
 		)
 	return q
 }
 
+// Cache 开启查询缓存，First、AllX 的结果缓存 ttl 时长，需要客户端通过 WithCache 设置了缓存
+func (q *UserQuery) Cache(ttl time.Duration) *UserQuery {
+	q.ttl = ttl
+	return q
+}
+
+// cacheGet 读取缓存，返回缓存数据的深拷贝
+func (q *UserQuery) cacheGet(query string, args []any) (any, bool) {
+	if q.cache == nil || q.ttl <= 0 {
+		return nil, false
+	}
+	v, ok := q.cache.Get(q.cacheKey(query, args))
+	if !ok {
+		return nil, false
+	}
+	return esql.DeepCopy(v), true
+}
+
+// cacheSet 写入结果的深拷贝，调用方之后的修改不会影响缓存
+func (q *UserQuery) cacheSet(query string, args []any, v any) {
+	if q.cache == nil || q.ttl <= 0 {
+		return
+	}
+	q.cache.Set(q.cacheKey(query, args), esql.DeepCopy(v), q.ttl, q.cacheTables()...)
+}
+
+func (q *UserQuery) cacheKey(query string, args []any) string {
+	var with []string
+	for k := range q.with {
+		with = append(with, k)
+	}
+	return esql.CacheKey(query, args, with...)
+}
+
+// cacheTables 返回查询结果依赖的表，任意一张表发生写入时缓存失效
+func (q *UserQuery) cacheTables() []string {
+	tables := []string{TableName}
+	if _, ok := q.with["role"]; ok {
+		tables = append(tables, EdgeRoleTableName, EdgeRoleAccessTableName)
+	}
+	return tables
+}
+
 func (q *UserQuery) queryWith(ctx context.Context, data []*UserData) error {
 	return nil
 }
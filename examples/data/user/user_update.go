@@ -3,13 +3,16 @@ package user
 
 import (
 	"context"
+	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
 	"github.com/go-kenka/esql"
 )
 
 type UserUpdate struct {
 	builder *sql.UpdateBuilder
+	where   []*sql.Predicate // MySQL 更新后按条件查询更新前的主键
 	db      esql.Driver
+	cache   esql.Cache
 	data    *UserData
 }
 
@@ -27,6 +30,7 @@ func (u *UserUpdate) Add(column string, v any) *UserUpdate {
 }
 
 func (u *UserUpdate) Where(p *sql.Predicate) *UserUpdate {
+	u.where = append(u.where, p)
 	u.builder.Where(p)
 	return u
 }
@@ -37,24 +41,66 @@ func (u *UserUpdate) Save(ctx context.Context) ([]*UserData, error) {
 }
 
 func (u *UserUpdate) sqlSave(ctx context.Context) ([]*UserData, error) {
+	if u.builder.Dialect() == dialect.MySQL {
+		return u.mysqlSave(ctx)
+	}
 	query, args := u.builder.Query()
-	stmt, err := u.db.Preparex(query)
+	stmt, err := u.db.PreparexContext(ctx, query)
 	if err != nil {
-
 		return nil, err
 	}
+	defer stmt.Close()
 	var data []*UserData
 
-	err = stmt.SelectContext(ctx, data, args...)
+	err = stmt.SelectContext(ctx, &data, args...)
 	if err != nil {
 		return nil, err
 	}
+	if u.cache != nil {
+		u.cache.Invalidate(TableName)
+	}
+	return data, nil
+}
+
+// mysqlSave MySQL 不支持 RETURNING，先按条件查询需要更新的数据，执行更新后按主键重新查询
+func (u *UserUpdate) mysqlSave(ctx context.Context) ([]*UserData, error) {
+	selector := sql.Dialect(dialect.MySQL).Select(Columns...).From(sql.Table(TableName))
+	if len(u.where) > 0 {
+		selector.Where(sql.And(u.where...))
+	}
+	query, args := selector.Query()
+	var rows []*UserData
+	if err := esql.SelectContext(ctx, u.db, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	query, args = u.builder.Query()
+	if _, err := u.db.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+	if u.cache != nil {
+		u.cache.Invalidate(TableName)
+	}
+	ids := make([]any, 0, len(rows))
+	for _, d := range rows {
+		ids = append(ids, d.Id)
+	}
+	query, args = sql.Dialect(dialect.MySQL).Select(Columns...).From(sql.Table(TableName)).Where(sql.In(ColumnId, ids...)).Query()
+	var data []*UserData
+	if err := esql.SelectContext(ctx, u.db, &data, query, args...); err != nil {
+		return nil, err
+	}
 	return data, nil
 }
 
 type UserUpdateOne struct {
 	builder *sql.UpdateBuilder
+	key     *sql.Predicate // MySQL 更新后按主键重新查询
 	db      esql.Driver
+	cache   esql.Cache
 	data    *UserData
 }
 
@@ -77,17 +123,41 @@ func (u *UserUpdateOne) Save(ctx context.Context) (*UserData, error) {
 }
 
 func (u *UserUpdateOne) sqlSave(ctx context.Context) (*UserData, error) {
+	if u.builder.Dialect() == dialect.MySQL {
+		return u.mysqlSave(ctx)
+	}
 	query, args := u.builder.Query()
-	stmt, err := u.db.Preparex(query)
+	stmt, err := u.db.PreparexContext(ctx, query)
 	if err != nil {
-
 		return nil, err
 	}
+	defer stmt.Close()
 	var data UserData
 
-	err = stmt.SelectContext(ctx, &data, args...)
+	err = stmt.GetContext(ctx, &data, args...)
 	if err != nil {
 		return nil, err
 	}
+	if u.cache != nil {
+		u.cache.Invalidate(TableName)
+	}
+	return &data, nil
+}
+
+// mysqlSave MySQL 不支持 RETURNING，执行更新后按主键重新查询
+func (u *UserUpdateOne) mysqlSave(ctx context.Context) (*UserData, error) {
+	query, args := u.builder.Query()
+	if _, err := u.db.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+	if u.cache != nil {
+		u.cache.Invalidate(TableName)
+	}
+
+	query, args = sql.Dialect(dialect.MySQL).Select(Columns...).From(sql.Table(TableName)).Where(u.key).Query()
+	var data UserData
+	if err := esql.GetContext(ctx, u.db, &data, query, args...); err != nil {
+		return nil, err
+	}
 	return &data, nil
 }
@@ -16,6 +16,11 @@ type Client struct {
 //go:embed template/*
 var tmpl embed.FS
 
+// tableDir 表生成代码的目录
+func tableDir(base string, t *Table) string {
+	return filepath.Join(base, filepath.FromSlash(t.Package()))
+}
+
 func GenClient(base, pkg string, tbs []*Table) error {
 
 	err := os.MkdirAll(base, os.ModePerm)
@@ -32,17 +37,30 @@ func GenClient(base, pkg string, tbs []*Table) error {
 		if err := genData(base, tb); err != nil {
 			return err
 		}
-		if err := genCreate(base, tb); err != nil {
+		if err := genQuery(base, tb); err != nil {
 			return err
 		}
-		if err := genDelete(base, tb); err != nil {
+		if err := genQueries(base, tb); err != nil {
 			return err
 		}
-		if err := genQuery(base, tb); err != nil {
+		if err := genTree(base, tb); err != nil {
 			return err
 		}
-		if err := genUpdate(base, tb); err != nil {
-			return err
+		if tb.View {
+			// 视图只读，删除由表改为视图之前生成的文件
+			for _, suffix := range []string{"create", "delete", "update"} {
+				os.Remove(filepath.Join(tableDir(base, tb), fmt.Sprintf("%s_%s.go", tb.Name, suffix)))
+			}
+		} else {
+			if err := genCreate(base, tb); err != nil {
+				return err
+			}
+			if err := genDelete(base, tb); err != nil {
+				return err
+			}
+			if err := genUpdate(base, tb); err != nil {
+				return err
+			}
 		}
 		fmt.Printf("正在生成第%d个表的数据生成成功\n", i+1)
 	}
@@ -8,7 +8,7 @@ import (
 )
 
 func genCreate(base string, t *Table) error {
-	dir := filepath.Join(base, t.Name)
+	dir := tableDir(base, t)
 	genFile := filepath.Join(fmt.Sprintf("%s/%s_create.go", dir, t.Name))
 
 	// 生成之前，先删除文件
@@ -16,9 +16,23 @@ func genCreate(base string, t *Table) error {
 
 	tmp := template.New("create.tmpl")
 	tmp.Funcs(template.FuncMap{
-		"camelCase": CamelCase,
-		"goType":    GoType,
-		"lower":     Lower,
+		"camelCase":       CamelCase,
+		"goType":          GoType,
+		"lower":           Lower,
+		"isInt":           IsInt,
+		"idField":         IDField,
+		"defaultFuncs":    DefaultFuncs,
+		"createImports":   CreateImports,
+		"idType":          IDType,
+		"isCompositeKey":  IsCompositeKey,
+		"keyFields":       KeyFields,
+		"funcCall":        FuncCall,
+		"hasValidate":     HasValidate,
+		"hasTypedJSON":    HasTypedJSON,
+		"requiredFields":  RequiredFields,
+		"sensitiveFields": SensitiveFields,
+		"goTypeFields":    GoTypeFields,
+		"customType":      CustomType,
 	})
 	tmp, err := tmp.ParseFS(tmpl, "template/create.tmpl")
 	if err != nil {
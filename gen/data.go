@@ -8,7 +8,7 @@ import (
 )
 
 func genData(base string, t *Table) error {
-	dir := filepath.Join(base, t.Name)
+	dir := tableDir(base, t)
 	genFile := filepath.Join(fmt.Sprintf("%s/%s.go", dir, t.Name))
 
 	// 生成之前，先删除文件
@@ -16,12 +16,52 @@ func genData(base string, t *Table) error {
 
 	tmp := template.New("data.tmpl")
 	tmp.Funcs(template.FuncMap{
-		"camelCase": CamelCase,
-		"goType":    GoType,
-		"lower":     Lower,
-		"add":       Add,
-		"hasTime":   HasTime,
-		"hasJson":   HasJson,
+		"camelCase":       CamelCase,
+		"goType":          GoType,
+		"lower":           Lower,
+		"add":             Add,
+		"defaultFuncs":    DefaultFuncs,
+		"fieldType":       FieldType,
+		"isEnum":          IsEnum,
+		"enumType":        EnumType,
+		"enumConst":       EnumConst,
+		"hasEnum":         HasEnum,
+		"hasValidate":     HasValidate,
+		"validateFields":  ValidateFields,
+		"validatorChecks": ValidatorChecks,
+		"patterns":        Patterns,
+		"requiredFields":  RequiredFields,
+		"immutableFields": ImmutableFields,
+		"isPointer":       IsPointer,
+		"sensitiveFields": SensitiveFields,
+		"isJSON":          IsJSON,
+		"isTypedJSON":     IsTypedJSON,
+		"hasTypedJSON":    HasTypedJSON,
+		"typedJSONFields": TypedJSONFields,
+		"displayType":     DisplayType,
+		"joinType":        JoinType,
+		"joins":           Joins,
+		"children":        Children,
+		"edgeJoins":       EdgeJoins,
+		"isPoly":          IsPoly,
+		"isTarget":        IsTarget,
+		"isComputed":      IsComputed,
+		"statsFields":     StatsFields,
+		"statsType":       StatsType,
+		"dataImports":     DataImports,
+		"customTypes":     CustomTypes,
+		"customType":      CustomType,
+		"goTypeFields":    GoTypeFields,
+		"idType":          IDType,
+		"refType":         RefType,
+		"joinRefType":     JoinRefType,
+		"joinRefValid":    JoinRefValid,
+		"joinDisplay":     JoinDisplay,
+		"isCompositeKey":  IsCompositeKey,
+		"keyFields":       KeyFields,
+		"keyType":         KeyType,
+		"keyParams":       KeyParams,
+		"keyPredicate":    KeyPredicate,
 	})
 	tmp, err := tmp.ParseFS(tmpl, "template/data.tmpl")
 	if err != nil {
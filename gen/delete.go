@@ -8,7 +8,7 @@ import (
 )
 
 func genDelete(base string, t *Table) error {
-	dir := filepath.Join(base, t.Name)
+	dir := tableDir(base, t)
 	genFile := filepath.Join(fmt.Sprintf("%s/%s_delete.go", dir, t.Name))
 
 	// 生成之前，先删除文件
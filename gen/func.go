@@ -1,9 +1,17 @@
 package gen
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/go-kenka/esql/dsl"
 	"github.com/gobeam/stringy"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 func CamelCase(str string) string {
@@ -23,51 +31,1023 @@ func IsString(t dsl.Type) bool {
 	return t == dsl.TypeString || t == dsl.TypeEnum || t == dsl.TypeTime
 }
 
-func HasTime(t *Table) bool {
+// IsInt 是否为整数类型
+func IsInt(t dsl.Type) bool {
+	return t >= dsl.TypeInt8 && t <= dsl.TypeUint64
+}
+
+// IDField 返回表的主键字段，ReadDir 保证主键字段位于首位
+func IDField(t *Table) *Field {
 	for _, field := range t.Fields {
-		if field.TypeInfo == dsl.TypeTime {
+		if field.Name == "id" {
+			return field
+		}
+	}
+	return t.Fields[0]
+}
+
+// IDType 主键在生成代码中使用的Go类型，例如 int64、string、uuid.UUID，复合主键使用生成的结构体，例如 UserRoleKey
+func IDType(t *Table) string {
+	if IsCompositeKey(t) {
+		return CamelCase(t.Name) + "Key"
+	}
+	return baseType(IDField(t))
+}
+
+// IsCompositeKey 表是否通过 PrimaryKey 定义了复合主键
+func IsCompositeKey(t *Table) bool {
+	return len(t.PrimaryKey) > 0
+}
+
+// KeyFields 主键包含的字段，按 PrimaryKey 定义的顺序排列
+func KeyFields(t *Table) []*Field {
+	if !IsCompositeKey(t) {
+		return []*Field{IDField(t)}
+	}
+	var fields []*Field
+	for _, name := range t.PrimaryKey {
+		for _, field := range t.Fields {
+			if field.Name == name {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// KeyIndexes 主键字段在表字段中的位置，迁移文件中使用
+func KeyIndexes(t *Table) []int {
+	if !IsCompositeKey(t) {
+		return []int{0}
+	}
+	var indexes []int
+	for _, name := range t.PrimaryKey {
+		for i, field := range t.Fields {
+			if field.Name == name {
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	return indexes
+}
+
+// KeyType 主键字段的Go类型
+func KeyType(f *Field) string {
+	return baseType(f)
+}
+
+// ParamName 字段作为函数参数时的名称，例如 tenant_id 使用 tenantId
+func ParamName(f *Field) string {
+	name := CamelCase(f.Name)
+	r, size := utf8.DecodeRuneInString(name)
+	name = string(unicode.ToLower(r)) + name[size:]
+	if token.IsKeyword(name) {
+		name += "_"
+	}
+	return name
+}
+
+// KeyParams 按主键定位数据的函数参数，例如 tenantId int, id int
+func KeyParams(t *Table) string {
+	var params []string
+	for _, field := range KeyFields(t) {
+		params = append(params, ParamName(field)+" "+KeyType(field))
+	}
+	return strings.Join(params, ", ")
+}
+
+// KeyPredicate 根据 KeyParams 中的参数生成的主键查询条件
+func KeyPredicate(t *Table) string {
+	if !IsCompositeKey(t) {
+		return "sql.EQ(ColumnId, id)"
+	}
+	var fields []string
+	for _, field := range KeyFields(t) {
+		fields = append(fields, CamelCase(field.Name)+": "+ParamName(field))
+	}
+	return fmt.Sprintf("%s{%s}.predicate()", IDType(t), strings.Join(fields, ", "))
+}
+
+// IDImports 主键类型需要的导入声明，复合主键的结构体与数据定义在同一个包中
+func IDImports(t *Table) []string {
+	if IsCompositeKey(t) {
+		return nil
+	}
+	id := IDField(t)
+	switch {
+	case id.GoType != nil && id.GoType.PkgPath != "":
+		return []string{importSpec(id.GoType.PkgPath)}
+	case id.GoType == nil && id.TypeInfo == dsl.TypeUUID:
+		return []string{uuidImport}
+	}
+	return nil
+}
+
+// CreateImports 创建文件中 DefaultFunc 以及主键类型需要的导入声明
+func CreateImports(t *Table) []string {
+	imports := FuncImports(t)
+	for _, spec := range append(IDImports(t), GoTypeImports(t)...) {
+		if !contains(imports, spec) {
+			imports = append(imports, spec)
+		}
+	}
+	return imports
+}
+
+// RefType 关系数据中 Ref 字段的Go类型
+func RefType(e *Edge) string {
+	if e.RefField == nil {
+		return GoType(dsl.TypeInt)
+	}
+	return baseType(e.RefField)
+}
+
+// JoinRefType M2O、O2O 关系左连接查询的 Ref 字段的Go类型，没有关联数据时为NULL
+func JoinRefType(e *Edge) string {
+	if e.RefField == nil {
+		return "*" + GoType(dsl.TypeInt)
+	}
+	return JoinType(e.RefField)
+}
+
+// JoinRefValid 左连接查询到关联数据时为true的Go表达式，expr 为 Ref 字段的Go表达式
+func JoinRefValid(e *Edge, expr string) string {
+	if strings.HasPrefix(JoinRefType(e), "stdSql.") {
+		return expr + ".Valid"
+	}
+	return expr + " != nil"
+}
+
+// JoinDisplay 左连接查询的 Display 字段，与 Ref 同名的字段已经作为 Ref 查询，不再重复
+func JoinDisplay(j *Join) []*Field {
+	if j.Parent == nil && j.Type != TypeM2O && j.Type != TypeO2O {
+		return j.Display
+	}
+	var fields []*Field
+	for _, d := range j.Display {
+		if d.Name != j.Ref {
+			fields = append(fields, d)
+		}
+	}
+	return fields
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
 			return true
 		}
 	}
+	return false
+}
 
+// WithCheck queryWith 是否不需要查询关联数据，只有 O2M、M2M 关系需要单独查询，
+// M2O、O2O 关系在主查询中通过左连接查询
+func WithCheck(t *Table) bool {
 	for _, edge := range t.Edges {
-		for _, field := range edge.Display {
-			if field.TypeInfo == dsl.TypeTime {
-				return true
+		if edge.Type == TypeO2M || edge.Type == TypeM2M || edge.Type == TypePoly {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TreeEdge 指向同一张表主键的 M2O 关系，例如 parent_id 指向 id，存在时生成 Ancestors、Descendants 递归查询，
+// 有多个时使用第一个，复合主键以及分片的表不生成
+func TreeEdge(t *Table) *Edge {
+	if IsCompositeKey(t) || t.ShardKey != "" {
+		return nil
+	}
+	for _, e := range t.Edges {
+		if e.Type == TypeM2O && e.From == t.Name && e.Ref == "id" {
+			return e
+		}
+	}
+	return nil
+}
+
+// Joins 表的所有关系按路径深度优先展开，表的别名为 t1，关系依次为 t2、t3...
+func Joins(t *Table) []*Join {
+	var joins []*Join
+	var walk func(parent *Join, e *Edge)
+	walk = func(parent *Join, e *Edge) {
+		j := &Join{Edge: e, Parent: parent, Prefix: e.Name}
+		if parent != nil {
+			j.Prefix = parent.Prefix + "__" + e.Name
+			j.Depth = parent.Depth + 1
+		}
+		j.Name = CamelCase(strings.ReplaceAll(j.Prefix, "__", "_"))
+		j.Alias = fmt.Sprintf("t%d", len(joins)+2)
+		joins = append(joins, j)
+		for _, r := range e.Relation {
+			walk(j, r)
+		}
+		for _, r := range e.Targets {
+			walk(j, r)
+		}
+	}
+	for _, e := range t.Edges {
+		walk(nil, e)
+	}
+	return joins
+}
+
+// IsPoly 是否为多态关系
+func IsPoly(e *Edge) bool {
+	return e.Type == TypePoly
+}
+
+// IsTarget 是否为多态关系指向的表，通过 Discriminator 的值分别查询，不使用左连接
+func IsTarget(j *Join) bool {
+	return j.Parent != nil && IsPoly(j.Parent.Edge)
+}
+
+// TableField 表中名称为 name 的字段，不存在时为nil
+func TableField(t *Table, name string) *Field {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// LinkType 关系的 Link 字段不考虑NULL时的Go类型
+func LinkType(t *Table, e *Edge) string {
+	if f := TableField(t, e.Link); f != nil {
+		return baseType(f)
+	}
+	return GoType(dsl.TypeInt)
+}
+
+// IsNull 可为NULL的字段 f 为NULL的条件，expr 为字段的Go表达式，字段不可为NULL时为空
+func IsNull(f *Field, expr string) string {
+	switch t := FieldType(f); {
+	case !f.Nillable:
+		return ""
+	case strings.HasPrefix(t, "stdSql."):
+		return "!" + expr + ".Valid"
+	default:
+		return expr + " == nil"
+	}
+}
+
+// NullValue 字段 f 不为NULL时的值，expr 为字段的Go表达式
+func NullValue(f *Field, expr string) string {
+	switch t := FieldType(f); {
+	case !f.Nillable:
+		return expr
+	case strings.HasPrefix(t, "stdSql.Null"):
+		return expr + "." + strings.TrimPrefix(t, "stdSql.Null")
+	case strings.HasPrefix(t, "*"):
+		return "*" + expr
+	default:
+		return expr
+	}
+}
+
+// EdgeJoins 关系 e 的 Relation 展开后的关系，不包含 e 自身
+func EdgeJoins(t *Table, e *Edge) []*Join {
+	var joins []*Join
+	for _, j := range Joins(t) {
+		for p := j.Parent; p != nil; p = p.Parent {
+			if p.Edge == e {
+				joins = append(joins, j)
+				break
 			}
 		}
 	}
+	return joins
+}
+
+// EdgeJoin 表的关系 e 对应的 Join
+func EdgeJoin(t *Table, e *Edge) *Join {
+	for _, j := range Joins(t) {
+		if j.Edge == e {
+			return j
+		}
+	}
+	return nil
+}
+
+// Children 直接下一级的关系
+func Children(t *Table, parent *Join) []*Join {
+	var joins []*Join
+	for _, j := range Joins(t) {
+		if j.Parent != nil && j.Parent.Prefix == parent.Prefix {
+			joins = append(joins, j)
+		}
+	}
+	return joins
+}
 
+// EdgeWhere 关系的条件，多个时使用 AND 连接，属于关系指向的表的字段加上表别名 alias，alias 为空时原样返回
+func EdgeWhere(e *Edge, alias string) string {
+	conds := make([]string, len(e.Where))
+	for i, w := range e.Where {
+		conds[i] = "(" + qualify(w, e.Target, alias) + ")"
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// EdgeOrder O2M、M2M 关联数据的排序，属于关系指向的表的字段加上表别名 alias
+func EdgeOrder(e *Edge, alias string) string {
+	orders := make([]string, len(e.Order))
+	for i, o := range e.Order {
+		orders[i] = qualify(o, e.Target, alias)
+	}
+	return strings.Join(orders, ", ")
+}
+
+// qualify 为 SQL 片段中属于表 t 的字段名加上表别名，字符串、注释、引用的标识符、函数名以及已经限定的名称不变
+func qualify(expr string, t *Table, alias string) string {
+	if t == nil || alias == "" {
+		return expr
+	}
+	masked := maskSQL(expr)
+	var b strings.Builder
+	for i := 0; i < len(masked); {
+		c := masked[i]
+		switch {
+		case c == '"' || c == '`':
+			j := strings.IndexByte(masked[i+1:], c)
+			if j < 0 {
+				j = len(masked) - i - 1
+			}
+			b.WriteString(expr[i : i+j+2])
+			i += j + 2
+		case isWord(c) && !isDigit(c) && (i == 0 || !isWord(masked[i-1])):
+			j := i
+			for j < len(masked) && isWord(masked[j]) {
+				j++
+			}
+			prev := strings.TrimRight(masked[:i], " \t\n")
+			next := strings.TrimLeft(masked[j:], " \t\n")
+			word := expr[i:j]
+			if hasColumn(t, word) && !strings.HasSuffix(prev, ".") && !strings.HasSuffix(prev, ":") &&
+				!strings.HasPrefix(next, ".") && !strings.HasPrefix(next, "(") {
+				b.WriteString(alias + ".")
+			}
+			b.WriteString(word)
+			i = j
+		default:
+			b.WriteByte(expr[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+func hasColumn(t *Table, name string) bool {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return true
+		}
+	}
 	return false
 }
 
-func HasJson(t *Table) bool {
+func Add(a, b int) int {
+	return a + b
+}
+
+// SchemaTables 迁移时创建的数据表，不包含视图
+func SchemaTables(tbs []*Table) []*Table {
+	var tables []*Table
+	for _, t := range tbs {
+		if !t.View {
+			tables = append(tables, t)
+		}
+	}
+	return tables
+}
+
+// SchemaViews 迁移时创建的视图，不包含 External 的视图
+func SchemaViews(tbs []*Table) []*Table {
+	var views []*Table
+	for _, t := range tbs {
+		if t.View && !t.External {
+			views = append(views, t)
+		}
+	}
+	return views
+}
+
+// TableOptions MySQL 建表语句的表选项，包括存储引擎以及由 Desc 生成的表注释，
+// 例如 ENGINE = InnoDB COMMENT = '订单'
+func TableOptions(t *Table) string {
+	var opts []string
+	if t.Engine != "" {
+		opts = append(opts, "ENGINE = "+t.Engine)
+	}
+	if t.Desc != "" {
+		opts = append(opts, "COMMENT = '"+strings.ReplaceAll(t.Desc, "'", "''")+"'")
+	}
+	return strings.Join(opts, " ")
+}
+
+// DefaultValue 迁移文件中字段默认值的Go字面量，没有默认值时返回空字符串
+func DefaultValue(f *Field) string {
+	if f.DefaultExpr != "" {
+		return fmt.Sprintf("schema.Expr(%q)", f.DefaultExpr)
+	}
+	switch v := f.Default.(type) {
+	case string:
+		// 时间以SQL字面量输出，避免被当作表达式
+		if f.TypeInfo == dsl.TypeTime {
+			return fmt.Sprintf("schema.Expr(%q)", "'"+strings.ReplaceAll(v, "'", "''")+"'")
+		}
+		return strconv.Quote(v)
+	case bool, json.Number:
+		return fmt.Sprint(v)
+	default:
+		return ""
+	}
+}
+
+// HasDefault 字段是否设置了默认值，只支持字符串、数字以及布尔类型，其他类型的默认值被忽略
+func HasDefault(f *Field) bool {
+	switch f.Default.(type) {
+	case string, bool, json.Number:
+		return true
+	}
+	return false
+}
+
+// DefaultFuncs 返回设置了 DefaultFunc 的字段
+func DefaultFuncs(t *Table) []*Field {
+	var fields []*Field
 	for _, field := range t.Fields {
-		if field.TypeInfo == dsl.TypeJSON {
-			return true
+		if field.DefaultFunc != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// FuncImports 返回 DefaultFunc 所在包的导入声明，例如 uuid "github.com/google/uuid"
+func FuncImports(t *Table) []string {
+	var imports []string
+	seen := make(map[string]bool)
+	for _, field := range DefaultFuncs(t) {
+		pkg, _ := splitFunc(field.DefaultFunc)
+		if seen[pkg] {
+			continue
+		}
+		seen[pkg] = true
+		imports = append(imports, importSpec(pkg))
+	}
+	return imports
+}
+
+// FuncCall 返回调用 DefaultFunc 的表达式，例如 uuid.New()
+func FuncCall(f *Field) string {
+	pkg, name := splitFunc(f.DefaultFunc)
+	return fmt.Sprintf("%s.%s()", pkgName(pkg), name)
+}
+
+// splitFunc 将 github.com/google/uuid.New 拆分为包路径和函数名
+func splitFunc(fn string) (string, string) {
+	i := strings.LastIndex(fn, "/")
+	j := strings.Index(fn[i+1:], ".")
+	if j < 0 {
+		return "", fn
+	}
+	return fn[:i+1+j], fn[i+2+j:]
+}
+
+// importSpec 包的导入声明，包名与路径的最后一段不一致时使用别名
+func importSpec(pkg string) string {
+	if name := pkgName(pkg); name != path.Base(pkg) {
+		return fmt.Sprintf("%s %q", name, pkg)
+	}
+	return strconv.Quote(pkg)
+}
+
+// pkgName 根据包路径生成导入时使用的包名，例如 gopkg.in/yaml.v3 使用 yaml，
+// github.com/oklog/ulid/v2 使用 ulid
+func pkgName(pkg string) string {
+	name := path.Base(pkg)
+	if isMajorVersion(name) && path.Dir(pkg) != "." {
+		name = path.Base(path.Dir(pkg))
+	}
+	if i := strings.IndexAny(name, ".-"); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// FieldType 表字段在生成的结构体中使用的Go类型
+func FieldType(f *Field) string {
+	if IsTypedJSON(f) {
+		t := f.JSONType.Type
+		if f.Nillable && !strings.HasPrefix(t, "*") && !strings.HasPrefix(t, "[]") && !strings.HasPrefix(t, "map[") {
+			return "*" + t
+		}
+		return t
+	}
+	t := baseType(f)
+	if IsEnum(f) {
+		t = EnumType(f)
+	}
+	if !f.Nillable {
+		return t
+	}
+	return nullType(f, t)
+}
+
+// DisplayType 关系中 Display 字段的Go类型，M2O、O2O 关系通过左连接查询，关联的数据可能不存在，字段总是可为NULL
+func DisplayType(e *Edge, d *Field) string {
+	if e.Type == TypeM2O || e.Type == TypeO2O {
+		return JoinType(d)
+	}
+	if d.Nillable {
+		return nullType(d, baseType(d))
+	}
+	return baseType(d)
+}
+
+// IsComputed 是否为通过 Count、Agg、Expr 计算得到的 Display 字段
+func IsComputed(d *Field) bool {
+	return d.Agg != nil || d.Expr != ""
+}
+
+// StatsFields 关系中计算得到的 Display 字段，通过按 Ref 分组的子查询左连接查询
+func StatsFields(e *Edge) []*Field {
+	var fields []*Field
+	for _, d := range e.Display {
+		if IsComputed(d) {
+			fields = append(fields, d)
 		}
 	}
+	return fields
+}
+
+// StatsType 计算得到的 Display 字段的Go类型，COUNT 没有关联数据时为0，其他字段没有关联数据时为NULL
+func StatsType(d *Field) string {
+	if d.Agg != nil && d.Agg.Func == dsl.AggCount {
+		return baseType(d)
+	}
+	return JoinType(d)
+}
+
+// AggFunc 聚合字段在子查询中的表达式，例如 sql.Max("last_login")
+func AggFunc(d *Field) string {
+	column := d.Agg.Column
+	if d.Agg.Func == dsl.AggCount {
+		column = "*"
+	}
+	return fmt.Sprintf("sql.%s(%q)", CamelCase(strings.ToLower(d.Agg.Func)), column)
+}
+
+// JoinType 通过左连接查询的字段的Go类型
+func JoinType(d *Field) string {
+	return nullType(d, baseType(d))
+}
+
+// baseType 字段不考虑NULL时的Go类型，设置了 GoType 时使用自定义类型
+func baseType(f *Field) string {
+	if f.GoType != nil {
+		return CustomType(f)
+	}
+	return GoType(f.TypeInfo)
+}
+
+// CustomType 自定义Go类型的限定名称，例如 decimal.Decimal
+func CustomType(f *Field) string {
+	if f.GoType.PkgPath == "" {
+		return f.GoType.Name
+	}
+	name := f.GoType.Name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return pkgName(f.GoType.PkgPath) + "." + name
+}
+
+// CustomTypes 返回表字段中使用的自定义Go类型，用于生成 sql.Scanner、driver.Valuer 的编译期检查
+func CustomTypes(t *Table) []string {
+	var types []string
+	seen := make(map[string]bool)
+	for _, field := range t.Fields {
+		if field.GoType == nil || seen[CustomType(field)] {
+			continue
+		}
+		seen[CustomType(field)] = true
+		types = append(types, CustomType(field))
+	}
+	return types
+}
+
+// GoTypeFields 使用自定义Go类型的字段，生成类型化的 Set 方法以及查询条件
+func GoTypeFields(t *Table) []*Field {
+	var fields []*Field
+	for _, field := range t.Fields {
+		if field.GoType != nil {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// GoTypeImports 自定义Go类型需要的导入声明
+func GoTypeImports(t *Table) []string {
+	var imports []string
+	for _, field := range GoTypeFields(t) {
+		if field.GoType.PkgPath == "" {
+			continue
+		}
+		if spec := importSpec(field.GoType.PkgPath); !contains(imports, spec) {
+			imports = append(imports, spec)
+		}
+	}
+	return imports
+}
+
+// uuidImport TypeUUID 字段使用 github.com/google/uuid 中的 UUID 类型
+const uuidImport = `"github.com/google/uuid"`
+
+// sqlNullTypes database/sql 中提供了Null类型的字段类型
+var sqlNullTypes = map[dsl.Type]string{
+	dsl.TypeString:  "NullString",
+	dsl.TypeInt64:   "NullInt64",
+	dsl.TypeInt32:   "NullInt32",
+	dsl.TypeInt16:   "NullInt16",
+	dsl.TypeUint8:   "NullByte",
+	dsl.TypeFloat64: "NullFloat64",
+	dsl.TypeBool:    "NullBool",
+	dsl.TypeTime:    "NullTime",
+}
+
+// nullType 可为NULL的字段类型，[]byte、json.RawMessage 本身可以表示NULL
+func nullType(f *Field, t string) string {
+	if f.GoType != nil {
+		return "*" + t
+	}
+	if f.TypeInfo == dsl.TypeBytes || f.TypeInfo == dsl.TypeJSON {
+		return t
+	}
+	if name, ok := sqlNullTypes[f.TypeInfo]; ok && f.NullType == dsl.NullSQL && !IsEnum(f) {
+		return "stdSql." + name
+	}
+	return "*" + t
+}
 
+// SupportsSQLNull database/sql 中是否有字段类型对应的Null类型
+func SupportsSQLNull(f *Field) bool {
+	_, ok := sqlNullTypes[f.TypeInfo]
+	return ok
+}
+
+// DataImports 根据结构体中字段使用的类型，返回数据文件需要的导入声明
+func DataImports(t *Table) []string {
+	var types []string
+	var custom []*Field
+	add := func(f *Field, typ string) {
+		types = append(types, typ)
+		if f.GoType != nil && f.GoType.PkgPath != "" {
+			custom = append(custom, f)
+		}
+	}
+	for _, field := range t.Fields {
+		add(field, FieldType(field))
+	}
 	for _, edge := range t.Edges {
-		for _, field := range edge.Display {
-			if field.TypeInfo == dsl.TypeJSON {
-				return true
+		if edge.RefField != nil {
+			add(edge.RefField, RefType(edge))
+		}
+		for _, d := range edge.Display {
+			if IsComputed(d) {
+				add(d, StatsType(d))
+			} else {
+				add(d, DisplayType(edge, d))
+			}
+		}
+	}
+	for _, j := range Joins(t) {
+		switch {
+		case j.Parent == nil:
+			if j.RefField != nil && (j.Type == TypeM2O || j.Type == TypeO2O) {
+				add(j.RefField, JoinRefType(j.Edge))
+			}
+		case IsTarget(j):
+			if j.RefField != nil {
+				add(j.RefField, RefType(j.Edge))
+			}
+			for _, d := range j.Display {
+				add(d, DisplayType(j.Parent.Edge, d))
+			}
+		default:
+			if j.RefField != nil {
+				add(j.RefField, JoinRefType(j.Edge))
+			}
+			for _, d := range j.Display {
+				add(d, JoinType(d))
 			}
 		}
 	}
 
-	return false
+	seen := make(map[string]bool)
+	var imports []string
+	use := func(spec string) {
+		if !seen[spec] {
+			seen[spec] = true
+			imports = append(imports, spec)
+		}
+	}
+	for _, typ := range types {
+		if spec, ok := typeImport(typ); ok {
+			use(spec)
+		}
+	}
+	for _, f := range custom {
+		use(importSpec(f.GoType.PkgPath))
+	}
+	for _, field := range t.Fields {
+		if !IsTypedJSON(field) {
+			continue
+		}
+		for name, pkg := range field.JSONType.Imports {
+			if name == path.Base(pkg) {
+				use(strconv.Quote(pkg))
+			} else {
+				use(fmt.Sprintf("%s %q", name, pkg))
+			}
+		}
+	}
+	for _, spec := range validatorImports(t) {
+		use(spec)
+	}
+	if HasJSON(t) {
+		use(`"entgo.io/ent/dialect/sql/sqljson"`)
+	}
+	if HasTypedJSON(t) {
+		use(`"encoding/json"`)
+	}
+	if HasEnum(t) || len(CustomTypes(t)) > 0 {
+		use(`"database/sql/driver"`)
+	}
+	use(`"fmt"`)
+	use(`"strings"`)
+	if len(CustomTypes(t)) > 0 {
+		use(`stdSql "database/sql"`)
+	}
+	sort.Strings(imports)
+	return imports
 }
-func WithCheck(t *Table) bool {
-	for _, edge := range t.Edges {
-		if edge.Type == TypeM2O || edge.Type == TypeO2O {
+
+// typePkgs 内置类型的前缀对应的导入声明
+var typePkgs = map[string]string{
+	"uuid.":   uuidImport,
+	"time.":   `"time"`,
+	"json.":   `"encoding/json"`,
+	"stdSql.": `stdSql "database/sql"`,
+}
+
+// typeImport 字段的Go类型需要的导入声明，自定义类型的导入由 importSpec 处理
+func typeImport(typ string) (string, bool) {
+	for prefix, spec := range typePkgs {
+		if strings.HasPrefix(strings.TrimPrefix(typ, "*"), prefix) {
+			return spec, true
+		}
+	}
+	return "", false
+}
+
+// IsEnum 是否为声明了取值的枚举字段，没有取值的枚举字段按字符串处理
+func IsEnum(f *Field) bool {
+	return f.TypeInfo == dsl.TypeEnum && len(f.Enums) > 0
+}
+
+// EnumType 枚举字段生成的Go类型名称，例如 status 字段生成 Status
+func EnumType(f *Field) string {
+	return CamelCase(f.Name)
+}
+
+// EnumConst 枚举取值生成的Go常量名称，例如 StatusActive
+func EnumConst(f *Field, e dsl.EnumValue) string {
+	name := e.Name
+	if name == "" {
+		name = e.Value
+	}
+	return EnumType(f) + CamelCase(name)
+}
+
+// EnumValues 枚举字段的全部取值
+func EnumValues(f *Field) []string {
+	var values []string
+	for _, e := range f.Enums {
+		values = append(values, e.Value)
+	}
+	return values
+}
+
+// HasEnum 表中是否有声明了取值的枚举字段
+func HasEnum(t *Table) bool {
+	for _, field := range t.Fields {
+		if IsEnum(field) {
 			return true
 		}
 	}
+	return false
+}
+
+// isMajorVersion 是否为模块路径中的主版本号，例如 v2
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, r := range s[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
 
+// IsJSON 是否为JSON字段
+func IsJSON(f *Field) bool {
+	return f.TypeInfo == dsl.TypeJSON
+}
+
+// IsTypedJSON 是否为通过 JSON 指定了Go类型的JSON字段
+func IsTypedJSON(f *Field) bool {
+	return f.TypeInfo == dsl.TypeJSON && f.JSONType != nil
+}
+
+// HasJSON 表中是否有JSON字段，需要生成JSON路径查询条件
+func HasJSON(t *Table) bool {
+	for _, field := range t.Fields {
+		if IsJSON(field) {
+			return true
+		}
+	}
 	return false
 }
 
-func Add(a, b int) int {
-	return a + b
+// TypedJSONFields 表中类型化的JSON字段，写入时需要序列化，查询后需要反序列化
+func TypedJSONFields(t *Table) []*Field {
+	var fields []*Field
+	for _, field := range t.Fields {
+		if IsTypedJSON(field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// HasTypedJSON 表中是否有类型化的JSON字段
+func HasTypedJSON(t *Table) bool {
+	return len(TypedJSONFields(t)) > 0
+}
+
+// HasValidate 写入数据前是否需要检查或转换字段的值
+func HasValidate(t *Table) bool {
+	if t.View {
+		return false
+	}
+	return len(ValidateFields(t)) > 0 || HasTypedJSON(t)
+}
+
+// ValidateFields 写入数据前需要检查取值的字段，包括枚举字段和设置了校验规则的字段
+func ValidateFields(t *Table) []*Field {
+	var fields []*Field
+	for _, field := range t.Fields {
+		if IsEnum(field) || len(field.Validators) > 0 {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// RequiredFields 创建时必须赋值的字段，可为NULL、设置了默认值以及 Optional 的字段除外，
+// 整数类型的单一主键由数据库自增生成，视图没有必填字段
+func RequiredFields(t *Table) []*Field {
+	if t.View {
+		return nil
+	}
+	var fields []*Field
+	for _, field := range t.Fields {
+		switch {
+		case field.Optional, field.Nillable:
+		case HasDefault(field), field.DefaultExpr != "", field.DefaultFunc != "":
+		case field.Name == "id" && !IsCompositeKey(t) && IsInt(field.TypeInfo):
+		default:
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// ImmutableFields 创建后不能修改的字段，视图只读，不需要检查
+func ImmutableFields(t *Table) []*Field {
+	if t.View {
+		return nil
+	}
+	var fields []*Field
+	for _, field := range t.Fields {
+		if field.Immutable {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// SensitiveFields 敏感字段，查询日志中隐藏写入的值
+func SensitiveFields(t *Table) []*Field {
+	var fields []*Field
+	for _, field := range t.Fields {
+		if field.Sensitive {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// IsPointer 字段在生成的结构体中是否使用指针
+func IsPointer(f *Field) bool {
+	return strings.HasPrefix(FieldType(f), "*")
+}
+
+// Pattern Match 校验规则生成的包级别正则表达式变量
+type Pattern struct {
+	Name string // 变量名称，例如 patternName
+	Expr string // 正则表达式
+}
+
+// Patterns 表中全部 Match 校验规则的正则表达式，按字段顺序命名，
+// 同一字段有多个 Match 时从第二个开始追加序号，例如 patternName、patternName2
+func Patterns(t *Table) []Pattern {
+	if !HasValidate(t) {
+		return nil
+	}
+	var patterns []Pattern
+	for _, field := range t.Fields {
+		n := 0
+		for _, v := range field.Validators {
+			if v.Name != dsl.ValidatorMatch {
+				continue
+			}
+			n++
+			patterns = append(patterns, Pattern{Name: patternName(field, n), Expr: v.Pattern})
+		}
+	}
+	return patterns
+}
+
+func patternName(f *Field, n int) string {
+	name := "pattern" + CamelCase(f.Name)
+	if n > 1 {
+		name += strconv.Itoa(n)
+	}
+	return name
+}
+
+// ValidatorChecks 字段的校验规则在生成代码中的调用表达式，v 为写入的值，
+// 例如 esql.MaxLen(v, 20)、esql.ValidateFunc(v, schema.CheckName)
+func ValidatorChecks(f *Field) []string {
+	var checks []string
+	n := 0
+	for _, v := range f.Validators {
+		switch v.Name {
+		case dsl.ValidatorMaxLen, dsl.ValidatorMinLen:
+			checks = append(checks, fmt.Sprintf("esql.%s(v, %d)", v.Name, v.Len))
+		case dsl.ValidatorNotEmpty, dsl.ValidatorEmail:
+			checks = append(checks, fmt.Sprintf("esql.%s(v)", v.Name))
+		case dsl.ValidatorMatch:
+			n++
+			checks = append(checks, fmt.Sprintf("esql.Match(v, %s)", patternName(f, n)))
+		case dsl.ValidatorRange:
+			checks = append(checks, fmt.Sprintf("esql.Range(v, %s, %s)", formatFloat(v.Min), formatFloat(v.Max)))
+		case dsl.ValidatorFunc:
+			pkg, name := splitFunc(v.Func)
+			checks = append(checks, fmt.Sprintf("esql.ValidateFunc(v, %s.%s)", pkgName(pkg), name))
+		}
+	}
+	return checks
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// validatorImports 校验规则需要的导入声明，包括正则表达式和 ValidateFunc 所在的包
+func validatorImports(t *Table) []string {
+	if !HasValidate(t) {
+		return nil
+	}
+	var imports []string
+	if len(Patterns(t)) > 0 {
+		imports = append(imports, `"regexp"`)
+	}
+	for _, field := range t.Fields {
+		for _, v := range field.Validators {
+			if v.Name == dsl.ValidatorFunc {
+				pkg, _ := splitFunc(v.Func)
+				imports = append(imports, importSpec(pkg))
+			}
+		}
+	}
+	return imports
 }
@@ -0,0 +1,380 @@
+package gen
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/go-kenka/esql/dsl"
+)
+
+func TestDefaultValue(t *testing.T) {
+	tests := []struct {
+		field *Field
+		want  string
+	}{
+		{&Field{TypeInfo: dsl.TypeString}, ""},
+		{&Field{TypeInfo: dsl.TypeString, Default: `a"b`}, `"a\"b"`},
+		{&Field{TypeInfo: dsl.TypeInt, Default: json.Number("-1")}, "-1"},
+		{&Field{TypeInfo: dsl.TypeBool, Default: false}, "false"},
+		{&Field{TypeInfo: dsl.TypeTime, Default: "2020-01-01 00:00:00"}, `schema.Expr("'2020-01-01 00:00:00'")`},
+		{&Field{TypeInfo: dsl.TypeTime, Default: "x", DefaultExpr: "CURRENT_TIMESTAMP"}, `schema.Expr("CURRENT_TIMESTAMP")`},
+	}
+	for _, tt := range tests {
+		if got := DefaultValue(tt.field); got != tt.want {
+			t.Errorf("DefaultValue(%v) = %s, want %s", tt.field.Default, got, tt.want)
+		}
+	}
+}
+
+func TestFuncCall(t *testing.T) {
+	tb := &Table{Fields: []*Field{
+		{Name: "id", DefaultFunc: "github.com/google/uuid.New"},
+		{Name: "created_at", DefaultFunc: "time.Now"},
+		{Name: "updated_at", DefaultFunc: "time.Now"},
+		{Name: "doc", DefaultFunc: "gopkg.in/yaml-v3.New"},
+		{Name: "code", DefaultFunc: "github.com/oklog/ulid/v2.Make"},
+	}}
+	if got := FuncCall(tb.Fields[0]); got != "uuid.New()" {
+		t.Errorf("FuncCall = %s", got)
+	}
+	if got := FuncCall(tb.Fields[4]); got != "ulid.Make()" {
+		t.Errorf("FuncCall = %s", got)
+	}
+	imports := FuncImports(tb)
+	want := []string{`"github.com/google/uuid"`, `"time"`, `yaml "gopkg.in/yaml-v3"`, `ulid "github.com/oklog/ulid/v2"`}
+	if len(imports) != len(want) {
+		t.Fatalf("FuncImports = %v, want %v", imports, want)
+	}
+	for i := range want {
+		if imports[i] != want[i] {
+			t.Errorf("FuncImports[%d] = %s, want %s", i, imports[i], want[i])
+		}
+	}
+}
+
+func TestFieldTypeJSON(t *testing.T) {
+	addr := &dsl.JSONTypeInfo{Type: "schema.Address", Imports: map[string]string{"schema": "example.com/app/schema"}}
+	tests := []struct {
+		field *Field
+		want  string
+	}{
+		{&Field{TypeInfo: dsl.TypeJSON}, "json.RawMessage"},
+		{&Field{TypeInfo: dsl.TypeJSON, Nillable: true}, "json.RawMessage"},
+		{&Field{TypeInfo: dsl.TypeJSON, JSONType: addr}, "schema.Address"},
+		{&Field{TypeInfo: dsl.TypeJSON, JSONType: addr, Nillable: true}, "*schema.Address"},
+		{&Field{TypeInfo: dsl.TypeJSON, JSONType: &dsl.JSONTypeInfo{Type: "[]string"}, Nillable: true}, "[]string"},
+	}
+	for _, tt := range tests {
+		if got := FieldType(tt.field); got != tt.want {
+			t.Errorf("FieldType(%+v) = %s, want %s", tt.field.JSONType, got, tt.want)
+		}
+	}
+}
+
+func TestKeyPredicate(t *testing.T) {
+	tb := &Table{Name: "user_role", PrimaryKey: []string{"user_id", "type"}, Fields: []*Field{
+		{Name: "type", TypeInfo: dsl.TypeString},
+		{Name: "user_id", TypeInfo: dsl.TypeInt64},
+	}}
+	if got, want := KeyParams(tb), "userId int64, type_ string"; got != want {
+		t.Errorf("KeyParams = %s, want %s", got, want)
+	}
+	if got, want := KeyPredicate(tb), "UserRoleKey{UserId: userId, Type: type_}.predicate()"; got != want {
+		t.Errorf("KeyPredicate = %s, want %s", got, want)
+	}
+	if got := KeyIndexes(tb); len(got) != 2 || got[0] != 1 || got[1] != 0 {
+		t.Errorf("KeyIndexes = %v", got)
+	}
+
+	tb = &Table{Name: "user", Fields: []*Field{{Name: "id", TypeInfo: dsl.TypeUUID}}}
+	if got, want := KeyParams(tb), "id uuid.UUID"; got != want {
+		t.Errorf("KeyParams = %s, want %s", got, want)
+	}
+	if got, want := KeyPredicate(tb), "sql.EQ(ColumnId, id)"; got != want {
+		t.Errorf("KeyPredicate = %s, want %s", got, want)
+	}
+}
+
+func TestValidatorChecks(t *testing.T) {
+	f := &Field{Name: "nick_name", TypeInfo: dsl.TypeString, Validators: []*dsl.ValidatorExpr{
+		dsl.MaxLen(20), {Name: dsl.ValidatorMatch, Pattern: `^\w+$`}, dsl.Range(0.5, 1e6),
+		{Name: dsl.ValidatorMatch, Pattern: `^a`}, {Name: dsl.ValidatorFunc, Func: "gopkg.in/check-v1.Nick"},
+	}}
+	want := []string{
+		"esql.MaxLen(v, 20)",
+		"esql.Match(v, patternNickName)",
+		"esql.Range(v, 0.5, 1e+06)",
+		"esql.Match(v, patternNickName2)",
+		"esql.ValidateFunc(v, check.Nick)",
+	}
+	got := ValidatorChecks(f)
+	if len(got) != len(want) {
+		t.Fatalf("ValidatorChecks = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ValidatorChecks[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+	if got := validatorImports(&Table{Fields: []*Field{f}}); len(got) != 2 || got[1] != `check "gopkg.in/check-v1"` {
+		t.Errorf("validatorImports = %v", got)
+	}
+}
+
+func TestRequiredFields(t *testing.T) {
+	tb := &Table{Fields: []*Field{
+		{Name: "id", TypeInfo: dsl.TypeInt},
+		{Name: "name", TypeInfo: dsl.TypeString},
+		{Name: "nick", TypeInfo: dsl.TypeString, Optional: true},
+		{Name: "note", TypeInfo: dsl.TypeString, Nillable: true},
+		{Name: "status", TypeInfo: dsl.TypeString, Default: "open"},
+		{Name: "created_at", TypeInfo: dsl.TypeTime, DefaultFunc: "time.Now"},
+		{Name: "tenant_id", TypeInfo: dsl.TypeInt},
+	}}
+	got := RequiredFields(tb)
+	if len(got) != 2 || got[0].Name != "name" || got[1].Name != "tenant_id" {
+		t.Errorf("RequiredFields = %v", got)
+	}
+
+	tb = &Table{Fields: []*Field{{Name: "id", TypeInfo: dsl.TypeUUID}}}
+	if got := RequiredFields(tb); len(got) != 1 {
+		t.Errorf("RequiredFields = %v, want id", got)
+	}
+}
+
+func TestTableOptions(t *testing.T) {
+	tests := []struct {
+		table *Table
+		want  string
+	}{
+		{&Table{}, ""},
+		{&Table{Engine: "InnoDB"}, "ENGINE = InnoDB"},
+		{&Table{Engine: "InnoDB", Desc: "user's order"}, "ENGINE = InnoDB COMMENT = 'user''s order'"},
+	}
+	for _, tt := range tests {
+		if got := TableOptions(tt.table); got != tt.want {
+			t.Errorf("TableOptions = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestSchemaViews(t *testing.T) {
+	tbs := []*Table{
+		{Name: "user"},
+		{Name: "user_stats", View: true, ViewSQL: "SELECT 1"},
+		{Name: "report", View: true, External: true},
+	}
+	if got := SchemaTables(tbs); len(got) != 1 || got[0].Name != "user" {
+		t.Errorf("SchemaTables = %v", got)
+	}
+	if got := SchemaViews(tbs); len(got) != 1 || got[0].Name != "user_stats" {
+		t.Errorf("SchemaViews = %v", got)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tb := &Table{Name: "user", Fields: []*Field{
+		{Name: "id", TypeInfo: dsl.TypeInt},
+		{Name: "username", TypeInfo: dsl.TypeString},
+		{Name: "role_id", TypeInfo: dsl.TypeInt},
+	}}
+	total := &Field{Name: "total", TypeInfo: dsl.TypeInt64}
+	tests := []struct {
+		sql     string
+		stmt    string
+		params  int
+		columns []string
+		all     bool
+	}{
+		{"SELECT * FROM user WHERE role_id = :role_id OR :role_id = 0", "SELECT * FROM user WHERE role_id = ? OR ? = 0", 1, []string{"id", "username", "role_id"}, true},
+		{"SELECT u.username, `id` AS id, t.role_id FROM user u -- :id\nWHERE username <> ':x'", "SELECT u.username, `id` AS id, t.role_id FROM user u -- :id\nWHERE username <> ':x'", 0, []string{"username", "id", "role_id"}, true},
+		{"SELECT DISTINCT username, COUNT(*) total FROM user WHERE id > :id::int GROUP BY username", "SELECT DISTINCT username, COUNT(*) total FROM user WHERE id > ?::int GROUP BY username", 1, []string{"username", "total"}, false},
+		{"WITH r AS (SELECT role_id FROM role) UPDATE user SET role_id = :role_id", "WITH r AS (SELECT role_id FROM role) UPDATE user SET role_id = ?", 1, nil, false},
+	}
+	for _, tt := range tests {
+		nq, err := ParseQuery(tb, &Query{Name: "Q", SQL: tt.sql, Fields: []*Field{total}})
+		if err != nil {
+			t.Errorf("ParseQuery(%q) = %v", tt.sql, err)
+			continue
+		}
+		if nq.Statement != tt.stmt || len(nq.Params) != tt.params || nq.AllColumns != tt.all {
+			t.Errorf("ParseQuery(%q) = %q, %d params, all %v", tt.sql, nq.Statement, len(nq.Params), nq.AllColumns)
+		}
+		if nq.Select != (tt.columns != nil) || len(nq.Columns) != len(tt.columns) {
+			t.Errorf("ParseQuery(%q) columns = %v, want %v", tt.sql, nq.Columns, tt.columns)
+			continue
+		}
+		for i, c := range tt.columns {
+			if nq.Columns[i].Name != c {
+				t.Errorf("ParseQuery(%q) column %d = %s, want %s", tt.sql, i, nq.Columns[i].Name, c)
+			}
+		}
+	}
+	if _, err := ParseQuery(tb, &Query{Name: "Q", SQL: "SELECT * FROM user WHERE id = ?"}); err == nil {
+		t.Error("ParseQuery with ? should fail")
+	}
+}
+
+func TestStatsFields(t *testing.T) {
+	e := &Edge{Display: []*Field{
+		{Name: "name", TypeInfo: dsl.TypeString},
+		{Name: "user_count", TypeInfo: dsl.TypeInt64, Agg: &dsl.AggExpr{Func: dsl.AggCount}},
+		{Name: "last_login", TypeInfo: dsl.TypeTime, Agg: &dsl.AggExpr{Func: dsl.AggMax, Column: "login_at"}},
+		{Name: "names", TypeInfo: dsl.TypeString, Expr: "GROUP_CONCAT(name)"},
+	}}
+	got := StatsFields(e)
+	if len(got) != 3 {
+		t.Fatalf("StatsFields = %v", got)
+	}
+	want := []string{"int64", "*time.Time", "*string"}
+	for i := range want {
+		if typ := StatsType(got[i]); typ != want[i] {
+			t.Errorf("StatsType(%s) = %s, want %s", got[i].Name, typ, want[i])
+		}
+	}
+	if got := AggFunc(e.Display[1]); got != `sql.Count("*")` {
+		t.Errorf("AggFunc = %s", got)
+	}
+	if got := AggFunc(e.Display[2]); got != `sql.Max("login_at")` {
+		t.Errorf("AggFunc = %s", got)
+	}
+}
+
+func TestTreeEdge(t *testing.T) {
+	parent := &Edge{Name: "parent", Type: TypeM2O, Link: "parent_id", From: "category", Ref: "id"}
+	tb := &Table{Name: "category", Fields: []*Field{{Name: "id"}, {Name: "name"}, {Name: "parent_id"}}, Edges: []*Edge{
+		{Name: "children", Type: TypeO2M, Link: "id", From: "category", Ref: "parent_id"},
+		parent,
+	}}
+	if got := TreeEdge(tb); got != parent {
+		t.Errorf("TreeEdge = %v, want parent", got)
+	}
+	if WithCheck(tb) {
+		t.Error("WithCheck = true, want false for a table with O2M edges")
+	}
+
+	tb.ShardKey = "id"
+	if got := TreeEdge(tb); got != nil {
+		t.Errorf("TreeEdge = %v, want nil for sharded table", got)
+	}
+}
+
+func TestJoins(t *testing.T) {
+	tb := &Table{Name: "user", Edges: []*Edge{
+		{Name: "role", Type: TypeM2O, Link: "role_id", From: "role", Ref: "id", Relation: []*Edge{
+			{Name: "access", Type: TypeM2O, Link: "access_id", From: "access", Ref: "id", Relation: []*Edge{
+				{Name: "group", Type: TypeM2O, Link: "group_id", From: "group", Ref: "id"},
+			}},
+		}},
+		{Name: "manager", Type: TypeM2O, Link: "manager_id", From: "user", Ref: "id", Relation: []*Edge{
+			{Name: "role", Type: TypeM2O, Link: "role_id", From: "role", Ref: "id"},
+		}},
+	}}
+
+	want := []struct{ name, prefix, alias, parent string }{
+		{"Role", "role", "t2", ""},
+		{"RoleAccess", "role__access", "t3", "role"},
+		{"RoleAccessGroup", "role__access__group", "t4", "role__access"},
+		{"Manager", "manager", "t5", ""},
+		{"ManagerRole", "manager__role", "t6", "manager"},
+	}
+	joins := Joins(tb)
+	if len(joins) != len(want) {
+		t.Fatalf("got %d joins, want %d", len(joins), len(want))
+	}
+	for i, j := range joins {
+		var parent string
+		if j.Parent != nil {
+			parent = j.Parent.Prefix
+		}
+		if j.Name != want[i].name || j.Prefix != want[i].prefix || j.Alias != want[i].alias || parent != want[i].parent {
+			t.Errorf("join %d = {%s %s %s %s}, want %v", i, j.Name, j.Prefix, j.Alias, parent, want[i])
+		}
+	}
+	if got := joins[2].Column(&Field{Name: "id"}); got != "role__access__group__id" {
+		t.Errorf("Column = %s, want role__access__group__id", got)
+	}
+	if got := EdgeJoins(tb, tb.Edges[0]); len(got) != 2 || got[1].Name != "RoleAccessGroup" {
+		t.Errorf("EdgeJoins = %v, want RoleAccess and RoleAccessGroup", got)
+	}
+	if got := Children(tb, joins[0]); len(got) != 1 || got[0].Name != "RoleAccess" {
+		t.Errorf("Children = %v, want RoleAccess", got)
+	}
+	// 与 Ref 同名的 Display 字段已经作为 Ref 查询
+	joins[2].Display = []*Field{{Name: "id"}, {Name: "name"}}
+	if got := JoinDisplay(joins[2]); len(got) != 1 || got[0].Name != "name" {
+		t.Errorf("JoinDisplay = %v, want name", got)
+	}
+}
+
+func TestEdgeWhere(t *testing.T) {
+	e := &Edge{
+		Where:  []string{"deleted_at IS NULL", "status = 'status' OR t1.status = status::text"},
+		Order:  []string{"created_at DESC", "LOWER(name)"},
+		Target: &Table{Fields: []*Field{{Name: "status"}, {Name: "deleted_at"}, {Name: "created_at"}, {Name: "name"}}},
+	}
+	tests := []struct{ got, want string }{
+		{EdgeWhere(e, "t3"), "(t3.deleted_at IS NULL) AND (t3.status = 'status' OR t1.status = t3.status::text)"},
+		{EdgeWhere(e, ""), "(deleted_at IS NULL) AND (status = 'status' OR t1.status = status::text)"},
+		{EdgeOrder(e, "t3"), "t3.created_at DESC, LOWER(t3.name)"},
+		{qualify("`status` = -- status\n status", e.Target, "t2"), "`status` = -- status\n t2.status"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestPolyEdge(t *testing.T) {
+	tb := &Table{Name: "comment", Fields: []*Field{
+		{Name: "owner_type", TypeInfo: dsl.TypeString, Nillable: true, NullType: dsl.NullSQL},
+		{Name: "owner_id", TypeInfo: dsl.TypeInt64, Nillable: true},
+	}, Edges: []*Edge{
+		{Name: "owner", Type: TypePoly, Link: "owner_id", Discriminator: "owner_type", Targets: []*Edge{
+			{Name: "post", Type: TypeM2O, From: "post", Ref: "id", Value: "post"},
+		}},
+	}}
+	joins := Joins(tb)
+	if len(joins) != 2 || !IsPoly(joins[0].Edge) || !IsTarget(joins[1]) || joins[1].Name != "OwnerPost" {
+		t.Fatalf("Joins = %v", joins)
+	}
+	if WithCheck(tb) {
+		t.Error("WithCheck = true, want false for a table with polymorphic edges")
+	}
+	if got := LinkType(tb, tb.Edges[0]); got != "int64" {
+		t.Errorf("LinkType = %s, want int64", got)
+	}
+
+	typ, id := tb.Fields[0], tb.Fields[1]
+	tests := []struct{ got, want string }{
+		{IsNull(typ, "d.OwnerType"), "!d.OwnerType.Valid"},
+		{NullValue(typ, "d.OwnerType"), "d.OwnerType.String"},
+		{IsNull(id, "d.OwnerId"), "d.OwnerId == nil"},
+		{NullValue(id, "d.OwnerId"), "*d.OwnerId"},
+		{IsNull(&Field{TypeInfo: dsl.TypeInt}, "d.Id"), ""},
+		{NullValue(&Field{TypeInfo: dsl.TypeInt}, "d.Id"), "d.Id"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestGoTypeImports(t *testing.T) {
+	tb := &Table{Name: "order", Fields: []*Field{
+		{Name: "id", TypeInfo: TypeInt},
+		{Name: "amount", GoType: &dsl.GoTypeInfo{PkgPath: "github.com/shopspring/decimal", Name: "Decimal"}},
+		{Name: "owner_id", TypeInfo: TypeString, GoType: &dsl.GoTypeInfo{PkgPath: "example.com/app/types", Name: "types.UserID"}},
+		{Name: "tax", GoType: &dsl.GoTypeInfo{PkgPath: "github.com/shopspring/decimal", Name: "Decimal"}},
+	}}
+	if got := GoTypeFields(tb); len(got) != 3 || got[0].Name != "amount" || CustomType(got[1]) != "types.UserID" {
+		t.Errorf("GoTypeFields = %v", got)
+	}
+	want := []string{`"github.com/shopspring/decimal"`, `"example.com/app/types"`}
+	if got := GoTypeImports(tb); !reflect.DeepEqual(got, want) {
+		t.Errorf("GoTypeImports = %v, want %v", got, want)
+	}
+}
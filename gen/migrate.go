@@ -48,10 +48,19 @@ func genSchema(base string, data *Client) error {
 
 	tmp := template.New("schema.tmpl")
 	tmp.Funcs(template.FuncMap{
-		"camelCase": CamelCase,
-		"dbType":    DBType,
-		"isString":  IsString,
-		"lower":     Lower,
+		"camelCase":      CamelCase,
+		"dbType":         DBType,
+		"isString":       IsString,
+		"lower":          Lower,
+		"defaultValue":   DefaultValue,
+		"isEnum":         IsEnum,
+		"enumValues":     EnumValues,
+		"isInt":          IsInt,
+		"isCompositeKey": IsCompositeKey,
+		"keyIndexes":     KeyIndexes,
+		"tableOptions":   TableOptions,
+		"tables":         SchemaTables,
+		"views":          SchemaViews,
 	})
 	tmp, err := tmp.ParseFS(tmpl, "template/schema.tmpl")
 	if err != nil {
@@ -0,0 +1,405 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-kenka/esql/dsl"
+)
+
+// queries 命名查询文件的模板数据
+type queries struct {
+	*Table
+	Named []*NamedQuery
+}
+
+func genQueries(base string, t *Table) error {
+	dir := tableDir(base, t)
+	genFile := filepath.Join(fmt.Sprintf("%s/%s_queries.go", dir, t.Name))
+
+	// 生成之前，先删除文件，没有命名查询时不生成
+	os.Remove(genFile)
+	if len(t.Queries) == 0 {
+		return nil
+	}
+
+	data := &queries{Table: t}
+	for _, q := range t.Queries {
+		nq, err := ParseQuery(t, q)
+		if err != nil {
+			return fmt.Errorf("query %s.%s: %w", t.Name, q.Name, err)
+		}
+		data.Named = append(data.Named, nq)
+	}
+
+	tmp := template.New("queries.tmpl")
+	tmp.Funcs(template.FuncMap{
+		"camelCase":      CamelCase,
+		"hasTypedJSON":   HasTypedJSON,
+		"paramType":      ParamType,
+		"rowType":        RowType,
+		"queriesImports": QueriesImports,
+	})
+	tmp, err := tmp.ParseFS(tmpl, "template/queries.tmpl")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	fs, err := os.OpenFile(genFile, os.O_WRONLY|os.O_CREATE, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	return tmp.Execute(fs, data)
+}
+
+// NamedQuery 解析后的命名查询
+type NamedQuery struct {
+	*Query
+	Const      string   // 保存语句的常量名称，例如 activeUsersByRoleQuery
+	Statement  string   // 参数替换为 ? 之后的语句，执行时按方言转换占位符
+	Params     []*Field // 参数，按首次出现的顺序排列，同名的参数只出现一次
+	Args       []*Field // 每个占位符对应的参数
+	Columns    []*Field // 查询语句的结果列
+	Select     bool     // 是否为查询语句，否则返回影响的行数
+	AllColumns bool     // 结果列与表的字段相同，结果使用表的 Data 结构体
+}
+
+// SensitiveParams 敏感字段对应的参数，不输出到查询日志
+func (q *NamedQuery) SensitiveParams() []*Field {
+	var fields []*Field
+	for _, f := range q.Params {
+		if f.Sensitive {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+var (
+	identPattern  = "(?:\\w+|\"[^\"]+\"|`[^`]+`|\\[[^\\]]+\\])"
+	aliasRegexp   = regexp.MustCompile(`(?is)^.+\s+AS\s+(` + identPattern + `)$`)
+	columnRegexp  = regexp.MustCompile(`^(?:` + identPattern + `\s*\.\s*)*(` + identPattern + `)$`)
+	implicitAlias = regexp.MustCompile(`^.*\)\s*(\w+)$`)
+	keywordRegexp = regexp.MustCompile(`(?i)^(SELECT|INSERT|UPDATE|DELETE|REPLACE|WITH)\b`)
+)
+
+// ParseQuery 解析命名查询的参数和结果列，参数以及结果列按名称对应表的字段或 Query 声明的字段
+func ParseQuery(t *Table, q *Query) (*NamedQuery, error) {
+	r, _ := utf8.DecodeRuneInString(q.Name)
+	nq := &NamedQuery{
+		Query: q,
+		Const: string(unicode.ToLower(r)) + q.Name[utf8.RuneLen(r):] + "Query",
+	}
+	masked := maskSQL(q.SQL)
+	if strings.TrimSpace(masked) == "" {
+		return nil, errors.New("statement is empty")
+	}
+
+	lookup := func(name string) *Field {
+		for _, f := range q.Fields {
+			if f.Name == name {
+				return f
+			}
+		}
+		for _, f := range t.Fields {
+			if f.Name == name {
+				return f
+			}
+		}
+		return nil
+	}
+
+	// 参数，:: 为 PostgreSQL 的类型转换
+	var b strings.Builder
+	last := 0
+	params := make(map[string]bool)
+	for i := 0; i < len(masked); i++ {
+		switch masked[i] {
+		case '?':
+			return nil, errors.New("positional parameter ? is not supported, use :name")
+		case ':':
+			if i+1 < len(masked) && masked[i+1] == ':' {
+				i++
+				continue
+			}
+			j := i + 1
+			for j < len(masked) && (masked[j] == '_' || isAlnum(masked[j])) {
+				j++
+			}
+			if j == i+1 || isDigit(masked[i+1]) {
+				continue
+			}
+			name := masked[i+1 : j]
+			f := lookup(name)
+			if f == nil {
+				return nil, fmt.Errorf("parameter :%s is not a field of table %s, declare its type in Query", name, t.Name)
+			}
+			if !params[name] {
+				params[name] = true
+				nq.Params = append(nq.Params, f)
+			}
+			nq.Args = append(nq.Args, f)
+			b.WriteString(q.SQL[last:i])
+			b.WriteString("?")
+			last = j
+			i = j - 1
+		}
+	}
+	b.WriteString(q.SQL[last:])
+	nq.Statement = strings.TrimSpace(b.String())
+
+	keyword, start := mainStatement(masked)
+	switch keyword {
+	case "SELECT":
+		nq.Select = true
+	case "INSERT", "UPDATE", "DELETE", "REPLACE":
+		return nq, nil
+	default:
+		return nil, errors.New("statement must be a SELECT, INSERT, UPDATE, DELETE or REPLACE")
+	}
+
+	// 结果列
+	end := len(masked)
+	if i := topLevelWord(masked, start, "FROM"); i >= 0 {
+		end = i
+	}
+	items := splitTopLevel(masked[start:end])
+	seen := make(map[string]bool)
+	for i, item := range items {
+		item = strings.TrimSpace(item)
+		if i == 0 {
+			for _, prefix := range []string{"DISTINCT ", "ALL "} {
+				if len(item) > len(prefix) && strings.EqualFold(item[:len(prefix)], prefix) {
+					item = strings.TrimSpace(item[len(prefix):])
+				}
+			}
+		}
+		if item == "*" || strings.HasSuffix(item, ".*") {
+			if len(items) > 1 {
+				return nil, errors.New("* cannot be combined with other result columns")
+			}
+			nq.Columns = t.Fields
+			nq.AllColumns = true
+			return nq, nil
+		}
+		name := resultName(item)
+		if name == "" {
+			return nil, fmt.Errorf("cannot determine the name of result column %q, alias it with AS", item)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("result column %s is duplicated", name)
+		}
+		seen[name] = true
+		f := lookup(name)
+		if f == nil {
+			return nil, fmt.Errorf("result column %s is not a field of table %s, declare its type in Query", name, t.Name)
+		}
+		nq.Columns = append(nq.Columns, f)
+	}
+	nq.AllColumns = sameFields(nq.Columns, t.Fields)
+	return nq, nil
+}
+
+// ParamType 命名查询参数的Go类型，可为NULL的字段使用非NULL的类型
+func ParamType(f *Field) string {
+	if IsEnum(f) {
+		return EnumType(f)
+	}
+	return baseType(f)
+}
+
+// RowType 命名查询结果列的Go类型，类型化的JSON字段使用原始的JSON数据
+func RowType(f *Field) string {
+	if IsTypedJSON(f) {
+		return GoType(dsl.TypeJSON)
+	}
+	return FieldType(f)
+}
+
+// QueriesImports 命名查询文件中参数、结果列的类型需要的导入声明
+func QueriesImports(q *queries) []string {
+	seen := make(map[string]bool)
+	var imports []string
+	add := func(f *Field, typ string) {
+		spec, ok := typeImport(typ)
+		if f.GoType != nil && f.GoType.PkgPath != "" {
+			spec, ok = importSpec(f.GoType.PkgPath), true
+		}
+		if ok && !seen[spec] {
+			seen[spec] = true
+			imports = append(imports, spec)
+		}
+	}
+	for _, nq := range q.Named {
+		for _, f := range nq.Params {
+			add(f, ParamType(f))
+		}
+		if nq.AllColumns {
+			continue
+		}
+		for _, f := range nq.Columns {
+			add(f, RowType(f))
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// maskSQL 将字符串字面量的内容和注释替换为空格，保持语句的长度不变
+func maskSQL(s string) string {
+	b := []byte(s)
+	fill := func(from, to int) {
+		for k := from; k < to && k < len(b); k++ {
+			if b[k] != '\n' {
+				b[k] = ' '
+			}
+		}
+	}
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == '\'':
+			j := strings.IndexByte(s[i+1:], '\'')
+			if j < 0 {
+				fill(i, len(b))
+				return string(b)
+			}
+			fill(i+1, i+j+1)
+			i += j + 1
+		case b[i] == '"' || b[i] == '`':
+			// 引用的标识符保留原样，跳过其中的内容
+			if j := strings.IndexByte(s[i+1:], b[i]); j >= 0 {
+				i += j + 1
+			}
+		case strings.HasPrefix(s[i:], "--"):
+			j := strings.IndexByte(s[i:], '\n')
+			if j < 0 {
+				j = len(s) - i
+			}
+			fill(i, i+j)
+			i += j
+		case strings.HasPrefix(s[i:], "/*"):
+			j := strings.Index(s[i+2:], "*/")
+			if j < 0 {
+				fill(i, len(b))
+				return string(b)
+			}
+			fill(i, i+j+4)
+			i += j + 3
+		}
+	}
+	return string(b)
+}
+
+// mainStatement 返回语句的类型以及其后的位置，WITH 开始的语句跳过公共表表达式
+func mainStatement(masked string) (string, int) {
+	s := strings.TrimLeft(masked, " \t\r\n(")
+	offset := len(masked) - len(s)
+	m := keywordRegexp.FindString(s)
+	if m == "" {
+		return "", 0
+	}
+	keyword := strings.ToUpper(m)
+	if keyword != "WITH" {
+		return keyword, offset + len(m)
+	}
+	// 公共表表达式位于括号中，之后第一个关键字为语句的类型，例如 WITH ... INSERT ... SELECT 为 INSERT
+	keyword, pos := "", -1
+	for _, k := range []string{"SELECT", "INSERT", "UPDATE", "DELETE", "REPLACE"} {
+		if i := topLevelWord(masked, offset+len(m), k); i >= 0 && (pos < 0 || i < pos) {
+			keyword, pos = k, i
+		}
+	}
+	if pos < 0 {
+		return "", 0
+	}
+	return keyword, pos + len(keyword)
+}
+
+// topLevelWord 从 start 开始查找不在括号中的关键字，不存在时返回 -1
+func topLevelWord(s string, start int, word string) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '"', '`':
+			if j := strings.IndexByte(s[i+1:], s[i]); j >= 0 {
+				i += j + 1
+			}
+		default:
+			if depth == 0 && i+len(word) <= len(s) && strings.EqualFold(s[i:i+len(word)], word) &&
+				(i == 0 || !isWord(s[i-1])) && (i+len(word) == len(s) || !isWord(s[i+len(word)])) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel 按不在括号中的逗号分割结果列
+func splitTopLevel(s string) []string {
+	var items []string
+	depth, last := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(items, s[last:])
+}
+
+// resultName 结果列的名称，依次使用 AS 的别名、列名以及函数调用之后的别名
+func resultName(item string) string {
+	for _, re := range []*regexp.Regexp{aliasRegexp, columnRegexp, implicitAlias} {
+		if m := re.FindStringSubmatch(item); m != nil {
+			return strings.Trim(m[1], "\"`[]")
+		}
+	}
+	return ""
+}
+
+// sameFields 结果列是否为表的全部字段，顺序可以不同
+func sameFields(columns, fields []*Field) bool {
+	if len(columns) != len(fields) {
+		return false
+	}
+	set := make(map[*Field]bool)
+	for _, f := range fields {
+		set[f] = true
+	}
+	for _, f := range columns {
+		if !set[f] {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+func isAlnum(c byte) bool { return isDigit(c) || c|0x20 >= 'a' && c|0x20 <= 'z' }
+func isWord(c byte) bool  { return c == '_' || isAlnum(c) }
@@ -8,7 +8,7 @@ import (
 )
 
 func genQuery(base string, t *Table) error {
-	dir := filepath.Join(base, t.Name)
+	dir := tableDir(base, t)
 	genFile := filepath.Join(fmt.Sprintf("%s/%s_query.go", dir, t.Name))
 
 	// 生成之前，先删除文件
@@ -16,10 +16,29 @@ func genQuery(base string, t *Table) error {
 
 	tmp := template.New("query.tmpl")
 	tmp.Funcs(template.FuncMap{
-		"camelCase": CamelCase,
-		"goType":    GoType,
-		"lower":     Lower,
-		"withCheck": WithCheck,
+		"camelCase":      CamelCase,
+		"goType":         GoType,
+		"lower":          Lower,
+		"withCheck":      WithCheck,
+		"hasTypedJSON":   HasTypedJSON,
+		"idType":         IDType,
+		"idImports":      IDImports,
+		"isCompositeKey": IsCompositeKey,
+		"keyFields":      KeyFields,
+		"edgeJoin":       EdgeJoin,
+		"isPoly":         IsPoly,
+		"refType":        RefType,
+		"joinDisplay":    JoinDisplay,
+		"tableField":     TableField,
+		"isNull":         IsNull,
+		"nullValue":      NullValue,
+		"linkType":       LinkType,
+		"edgeJoins":      EdgeJoins,
+		"edgeWhere":      EdgeWhere,
+		"edgeOrder":      EdgeOrder,
+		"isComputed":     IsComputed,
+		"statsFields":    StatsFields,
+		"aggFunc":        AggFunc,
 	})
 	tmp, err := tmp.ParseFS(tmpl, "template/query.tmpl")
 	if err != nil {
@@ -0,0 +1,44 @@
+package gen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+func genTree(base string, t *Table) error {
+	dir := tableDir(base, t)
+	genFile := filepath.Join(fmt.Sprintf("%s/%s_tree.go", dir, t.Name))
+
+	// 生成之前，先删除文件，没有自关联的 M2O 关系时不生成
+	os.Remove(genFile)
+	if TreeEdge(t) == nil {
+		return nil
+	}
+
+	tmp := template.New("tree.tmpl")
+	tmp.Funcs(template.FuncMap{
+		"camelCase": CamelCase,
+		"idType":    IDType,
+		"idImports": IDImports,
+		"treeEdge":  TreeEdge,
+	})
+	tmp, err := tmp.ParseFS(tmpl, "template/tree.tmpl")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	fs, err := os.OpenFile(genFile, os.O_WRONLY|os.O_CREATE, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	return tmp.Execute(fs, t)
+}
@@ -1,6 +1,10 @@
 package gen
 
-import "github.com/go-kenka/esql/dsl"
+import (
+	"path"
+
+	"github.com/go-kenka/esql/dsl"
+)
 
 const (
 	TypeInvalid = dsl.TypeInvalid
@@ -32,7 +36,7 @@ var (
 		TypeBool:    "bool",
 		TypeTime:    "time.Time",
 		TypeJSON:    "json.RawMessage",
-		TypeUUID:    "[16]byte",
+		TypeUUID:    "uuid.UUID",
 		TypeBytes:   "[]byte",
 		TypeEnum:    "string",
 		TypeString:  "string",
@@ -101,17 +105,19 @@ var (
 )
 
 const (
-	TypeO2O = dsl.TypeO2O
-	TypeO2M = dsl.TypeO2M
-	TypeM2O = dsl.TypeM2O
-	TypeM2M = dsl.TypeM2M
+	TypeO2O  = dsl.TypeO2O
+	TypeO2M  = dsl.TypeO2M
+	TypeM2O  = dsl.TypeM2O
+	TypeM2M  = dsl.TypeM2M
+	TypePoly = dsl.TypePoly
 )
 
 var EdgeTypeNameMap = map[string]dsl.EdgeType{
-	"TypeO2O": TypeO2O,
-	"TypeO2M": TypeO2M,
-	"TypeM2O": TypeM2O,
-	"TypeM2M": TypeM2M,
+	"TypeO2O":  TypeO2O,
+	"TypeO2M":  TypeO2M,
+	"TypeM2O":  TypeM2O,
+	"TypeM2M":  TypeM2M,
+	"TypePoly": TypePoly,
 }
 
 type Edge struct {
@@ -122,13 +128,67 @@ type Edge struct {
 	Ref      string
 	Display  []*Field
 	Relation []*Edge
+	Where    []string // 关联数据的条件
+	Order    []string // O2M、M2M 关联数据的排序
+	Limit    int      // O2M、M2M 每条数据最多关联的数量
+	// Discriminator 多态关系中区分指向的表的字段
+	Discriminator string
+	Targets       []*Edge // 多态关系指向的表
+	Value         string  // 多态关系指向的表在 Discriminator 字段中的值
+	Pos           dsl.Pos // 定义位置
+	RefField      *Field  // Ref 对应的字段，ReadDir 中解析，表或字段不存在时为nil
+	Target        *Table  // From 对应的表，ReadDir 中解析，不存在时为nil
+}
+
+// Join 关系以及按路径展开的 Relation，同一张表中 Name、Prefix、Alias 唯一
+type Join struct {
+	*Edge
+	Parent *Join  // 上一级关系，表的关系为nil
+	Name   string // 路径上关系名的驼峰形式，例如 RoleAccess
+	Prefix string // 左连接查询时结果列名的前缀，例如 role__access
+	Alias  string // 表别名，例如 t3
+	Depth  int    // 表的关系为0
+}
+
+// Column 左连接查询时 Display 字段在结果中的列名，例如 role__access__access_name
+func (j *Join) Column(d *Field) string {
+	return j.Prefix + "__" + d.Name
 }
 
 type Table struct {
-	Name   string   // 表名称
-	Fields []*Field // 表字段集合
-	Desc   string   // 备注
-	Edges  []*Edge  // 关系
+	Name     string   // 表名称
+	Fields   []*Field // 表字段集合
+	Desc     string   // 备注
+	Edges    []*Edge  // 关系
+	ShardKey string   // 分片键
+	Pos      dsl.Pos  // 定义位置
+	Dir      string   // 定义所在的schema子目录，例如 billing，schema根目录中的表为空
+
+	PrimaryKey []string // 复合主键的字段，单个 id 主键时为空
+
+	Checks    []dsl.CheckExpr // 检查约束
+	Charset   string          // 表的字符集
+	Collation string          // 表的排序规则
+	Engine    string          // 存储引擎
+
+	View     bool   // 是否为视图，视图只生成查询接口
+	ViewSQL  string // 视图的查询语句
+	External bool   // 视图由外部管理，迁移时不创建
+
+	Queries []*Query // 自定义的命名查询
+}
+
+// Package 生成的包相对目标目录的路径，schema子目录中的表生成到同名的子目录中，例如 billing/invoice
+func (t *Table) Package() string {
+	return path.Join(t.Dir, t.Name)
+}
+
+// Query 自定义的命名查询
+type Query struct {
+	Name   string   // 查询名称，即生成的方法名称
+	SQL    string   // 查询语句，参数使用 :name 的形式
+	Fields []*Field // 不属于表的结果列或参数
+	Pos    dsl.Pos  // 定义位置
 }
 
 type Field struct {
@@ -140,4 +200,25 @@ type Field struct {
 	Nillable bool        // 是否为NULL
 	Default  interface{} // 默认值
 	Comment  string      // 备注
+	Pos      dsl.Pos     // 定义位置
+
+	DefaultFunc string // 默认值函数的完整名称，例如 time.Now
+	DefaultExpr string // 数据库默认值表达式，例如 CURRENT_TIMESTAMP
+
+	Enums    []dsl.EnumValue // 枚举字段的取值
+	NullType dsl.NullKind    // 可为NULL时在结构体中的表示方式
+
+	GoType     *dsl.GoTypeInfo   // 自定义的Go类型
+	SchemaType map[string]string // 各数据库方言中的列类型
+
+	JSONType *dsl.JSONTypeInfo // 类型化的JSON字段的Go类型
+
+	Validators []*dsl.ValidatorExpr // 写入数据前执行的字段校验
+
+	Immutable bool // 创建后不能修改
+	Sensitive bool // 敏感字段，不输出到 String、JSON 以及查询日志
+	Optional  bool // 创建时可以不赋值
+
+	Agg  *dsl.AggExpr // 关系中计算得到的 Display 字段使用的聚合函数
+	Expr string       // 关系中计算得到的 Display 字段使用的SQL表达式
 }
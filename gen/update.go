@@ -8,7 +8,7 @@ import (
 )
 
 func genUpdate(base string, t *Table) error {
-	dir := filepath.Join(base, t.Name)
+	dir := tableDir(base, t)
 	genFile := filepath.Join(fmt.Sprintf("%s/%s_update.go", dir, t.Name))
 
 	// 生成之前，先删除文件
@@ -16,9 +16,17 @@ func genUpdate(base string, t *Table) error {
 
 	tmp := template.New("update.tmpl")
 	tmp.Funcs(template.FuncMap{
-		"camelCase": CamelCase,
-		"goType":    GoType,
-		"lower":     Lower,
+		"camelCase":       CamelCase,
+		"goType":          GoType,
+		"lower":           Lower,
+		"hasValidate":     HasValidate,
+		"hasTypedJSON":    HasTypedJSON,
+		"immutableFields": ImmutableFields,
+		"sensitiveFields": SensitiveFields,
+		"isCompositeKey":  IsCompositeKey,
+		"goTypeFields":    GoTypeFields,
+		"goTypeImports":   GoTypeImports,
+		"customType":      CustomType,
 	})
 	tmp, err := tmp.ParseFS(tmpl, "template/update.tmpl")
 	if err != nil {
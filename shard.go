@@ -0,0 +1,353 @@
+package esql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"entgo.io/ent/dialect/sql"
+)
+
+var (
+	// ErrNoShardKey 分片表的操作中没有找到分片键
+	ErrNoShardKey = errors.New("esql: shard key not found in predicates or values")
+	// ErrCrossShard 操作涉及多个分片，且没有开启 scatter-gather 模式
+	ErrCrossShard = errors.New("esql: operation spans multiple shards, call Scatter to allow it")
+	// ErrShardKeyUpdate 更新分片键会让数据跨分片移动，不允许修改
+	ErrShardKeyUpdate = errors.New("esql: shard key can not be updated")
+	// ErrShardTx 分片不在开启事务的数据库上，无法加入事务
+	ErrShardTx = errors.New("esql: shard is outside of the transaction database")
+)
+
+// Shard 分片，数据所在的数据库以及物理表名
+type Shard struct {
+	Driver Driver
+	Table  string
+}
+
+// Key 分片的唯一标识，用于区分不同分片上相同的语句
+func (s Shard) Key() string {
+	return fmt.Sprintf("%s@%p", s.Table, s.Driver)
+}
+
+// Rewrite 将语句中的逻辑表名替换为分片的物理表名
+func (s Shard) Rewrite(query, table string) string {
+	if s.Table == "" || s.Table == table {
+		return query
+	}
+	for _, quote := range []string{"`", `"`} {
+		query = replaceIdent(query, quote+table+quote, quote+s.Table+quote)
+	}
+	return query
+}
+
+// replaceIdent 替换表标识符，只替换 FROM、JOIN、INTO、UPDATE 之后的表名以及 `order`.`id` 中限定列的表名，
+// 与表同名的列（例如 order 表的 `order` 列）保持不变
+func replaceIdent(query, old, new string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(query, old)
+		if i < 0 {
+			b.WriteString(query)
+			return b.String()
+		}
+		b.WriteString(query[:i])
+		rest := query[i+len(old):]
+		if tablePosition(b.String()) || strings.HasPrefix(rest, ".") {
+			b.WriteString(new)
+		} else {
+			b.WriteString(old)
+		}
+		query = rest
+	}
+}
+
+// tablePosition 标识符之前的关键字是否表示其后为表名
+func tablePosition(prefix string) bool {
+	prefix = strings.TrimRight(prefix, " \t\n")
+	i := strings.LastIndexFunc(prefix, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	switch strings.ToUpper(prefix[i+1:]) {
+	case "FROM", "JOIN", "INTO", "UPDATE":
+		return true
+	}
+	return false
+}
+
+// ShardRouter 分片路由，根据分片键定位数据所在的分片
+type ShardRouter interface {
+	// Route 根据分片键的值返回对应的分片
+	Route(table string, key any) (Shard, error)
+	// Shards 返回表的全部分片，用于 scatter-gather 查询
+	Shards(table string) []Shard
+}
+
+// HashRouter 按分片键哈希取模的路由，支持分库以及按表名后缀分表
+type HashRouter struct {
+	drivers []Driver
+	tables  int
+}
+
+// NewHashRouter 创建哈希路由，tables 为分表总数，为0时只分库不分表。
+// 分表时 order_00..order_15 按顺序均匀分布在各个库上。
+func NewHashRouter(drivers []Driver, tables int) *HashRouter {
+	return &HashRouter{
+		drivers: drivers,
+		tables:  tables,
+	}
+}
+
+func (r *HashRouter) Route(table string, key any) (Shard, error) {
+	if len(r.drivers) == 0 {
+		return Shard{}, errors.New("esql: hash router has no drivers")
+	}
+	h, err := shardHash(key)
+	if err != nil {
+		return Shard{}, err
+	}
+	if r.tables <= 0 {
+		return Shard{Driver: r.drivers[h%uint64(len(r.drivers))], Table: table}, nil
+	}
+	return r.shard(table, int(h%uint64(r.tables))), nil
+}
+
+func (r *HashRouter) Shards(table string) []Shard {
+	var shards []Shard
+	if r.tables <= 0 {
+		for _, d := range r.drivers {
+			shards = append(shards, Shard{Driver: d, Table: table})
+		}
+		return shards
+	}
+	for i := 0; i < r.tables; i++ {
+		shards = append(shards, r.shard(table, i))
+	}
+	return shards
+}
+
+// TxRouter 事务中使用的分片路由，位于 db 上的分片改为在 tx 中执行，
+// 其他数据库上的分片无法加入事务，访问时返回 ErrShardTx。r 为nil时返回nil
+func TxRouter(r ShardRouter, db, tx Driver) ShardRouter {
+	if r == nil {
+		return nil
+	}
+	return &txRouter{router: r, db: db, tx: tx}
+}
+
+type txRouter struct {
+	router ShardRouter
+	db     Driver
+	tx     Driver
+}
+
+func (r *txRouter) Route(table string, key any) (Shard, error) {
+	s, err := r.router.Route(table, key)
+	if err != nil {
+		return Shard{}, err
+	}
+	if s.Driver != r.db {
+		return Shard{}, ErrShardTx
+	}
+	s.Driver = r.tx
+	return s, nil
+}
+
+func (r *txRouter) Shards(table string) []Shard {
+	shards := r.router.Shards(table)
+	for i := range shards {
+		if shards[i].Driver == r.db {
+			shards[i].Driver = r.tx
+		}
+	}
+	return shards
+}
+
+// check 检查 scatter-gather 访问的分片是否都在事务中
+func (r *txRouter) check(shards []Shard) error {
+	for _, s := range shards {
+		if s.Driver != r.tx {
+			return ErrShardTx
+		}
+	}
+	return nil
+}
+
+func (r *HashRouter) shard(table string, slot int) Shard {
+	width := len(strconv.Itoa(r.tables - 1))
+	if width < 2 {
+		width = 2
+	}
+	return Shard{
+		Driver: r.drivers[slot*len(r.drivers)/r.tables],
+		Table:  fmt.Sprintf("%s_%0*d", table, width, slot),
+	}
+}
+
+func shardHash(key any) (uint64, error) {
+	switch v := key.(type) {
+	case int:
+		return uint64(v), nil
+	case int8:
+		return uint64(v), nil
+	case int16:
+		return uint64(v), nil
+	case int32:
+		return uint64(v), nil
+	case int64:
+		return uint64(v), nil
+	case uint:
+		return uint64(v), nil
+	case uint8:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	case string:
+		return uint64(crc32.ChecksumIEEE([]byte(v))), nil
+	case []byte:
+		return uint64(crc32.ChecksumIEEE(v)), nil
+	case driver.Valuer:
+		dv, err := v.Value()
+		if err != nil {
+			return 0, err
+		}
+		return shardHash(dv)
+	case fmt.Stringer:
+		return uint64(crc32.ChecksumIEEE([]byte(v.String()))), nil
+	}
+	return 0, fmt.Errorf("esql: unsupported shard key type %T", key)
+}
+
+// ShardKeys 记录构建语句过程中出现的分片键取值
+type ShardKeys struct {
+	Column  string
+	keys    []any
+	any     bool
+	updated bool
+}
+
+// Set 记录 Create 写入的字段值，字段为分片键时保存其取值
+func (s *ShardKeys) Set(column string, v any) {
+	if column == s.Column {
+		s.keys = []any{v}
+	}
+}
+
+// Update 记录 Update 修改的字段，分片键被修改时 Resolve 返回 ErrShardKeyUpdate
+func (s *ShardKeys) Update(column string) {
+	if column == s.Column {
+		s.updated = true
+	}
+}
+
+// Add 显式指定分片键的值
+func (s *ShardKeys) Add(keys ...any) {
+	s.keys = append(s.keys, keys...)
+}
+
+// Where 从查询条件中提取分片键，只识别 AND 连接的 `=` 与 `IN` 条件
+func (s *ShardKeys) Where(p *sql.Predicate) {
+	keys, ok := PredicateKeys(p, s.Column)
+	if !ok {
+		return
+	}
+	s.keys = append(s.keys, keys...)
+}
+
+// Unknown 标记条件中的分片键已无法确定，例如使用了 Or/Not
+func (s *ShardKeys) Unknown() {
+	s.any = true
+}
+
+// Reset 清空已记录的分片键
+func (s *ShardKeys) Reset() {
+	s.keys = nil
+	s.any = false
+}
+
+// Clone 复制分片键记录
+func (s ShardKeys) Clone() ShardKeys {
+	s.keys = append([]any(nil), s.keys...)
+	return s
+}
+
+// Resolve 返回需要访问的分片，scatter 为 false 时拒绝跨分片的操作。
+// router 为nil时不分片，直接使用 db。
+func (s *ShardKeys) Resolve(r ShardRouter, db Driver, table string, scatter bool) ([]Shard, error) {
+	if s.updated {
+		return nil, ErrShardKeyUpdate
+	}
+	if r == nil {
+		return []Shard{{Driver: db, Table: table}}, nil
+	}
+	if len(s.keys) == 0 || s.any {
+		if !scatter {
+			return nil, ErrNoShardKey
+		}
+		shards := r.Shards(table)
+		if tr, ok := r.(*txRouter); ok {
+			if err := tr.check(shards); err != nil {
+				return nil, err
+			}
+		}
+		return shards, nil
+	}
+	var shards []Shard
+	for _, key := range s.keys {
+		shard, err := r.Route(table, key)
+		if err != nil {
+			return nil, err
+		}
+		if !containsShard(shards, shard) {
+			shards = append(shards, shard)
+		}
+	}
+	if len(shards) > 1 && !scatter {
+		return nil, ErrCrossShard
+	}
+	return shards, nil
+}
+
+func containsShard(shards []Shard, s Shard) bool {
+	for _, shard := range shards {
+		if shard.Table == s.Table && shard.Driver == s.Driver {
+			return true
+		}
+	}
+	return false
+}
+
+// PredicateKeys 从条件中提取字段的等值取值，条件包含 OR/NOT 时无法确定，返回false
+func PredicateKeys(p *sql.Predicate, column string) ([]any, bool) {
+	if p == nil {
+		return nil, false
+	}
+	query, args := p.Query()
+	upper := strings.ToUpper(query)
+	if strings.Contains(upper, " OR ") || strings.Contains(upper, "NOT (") {
+		return nil, false
+	}
+	re := regexp.MustCompile("(?:^|[\\s(.])`" + regexp.QuoteMeta(column) + "` (?:= \\?|IN \\(([?, ]+)\\))")
+	loc := re.FindStringSubmatchIndex(query)
+	if loc == nil {
+		return nil, false
+	}
+	start := strings.Count(query[:loc[0]], "?")
+	n := 1
+	if loc[2] >= 0 {
+		n = strings.Count(query[loc[2]:loc[3]], "?")
+	}
+	if start+n > len(args) {
+		return nil, false
+	}
+	return args[start : start+n], true
+}
@@ -0,0 +1,132 @@
+package esql
+
+import (
+	"reflect"
+	"testing"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestPredicateKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      *sql.Predicate
+		want   []any
+		wantOk bool
+	}{
+		{
+			name:   "eq",
+			p:      sql.EQ("user_id", 5),
+			want:   []any{5},
+			wantOk: true,
+		},
+		{
+			name:   "and",
+			p:      sql.And(sql.EQ("status", "paid"), sql.EQ("user_id", 7)),
+			want:   []any{7},
+			wantOk: true,
+		},
+		{
+			name:   "in",
+			p:      sql.In("user_id", 1, 2, 3),
+			want:   []any{1, 2, 3},
+			wantOk: true,
+		},
+		{
+			name:   "or",
+			p:      sql.Or(sql.EQ("user_id", 1), sql.EQ("status", "paid")),
+			wantOk: false,
+		},
+		{
+			name:   "other column",
+			p:      sql.EQ("other_user_id", 1),
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := PredicateKeys(tt.p, "user_id")
+			if ok != tt.wantOk || (ok && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("PredicateKeys() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestHashRouter(t *testing.T) {
+	r := NewHashRouter([]Driver{nil, nil}, 16)
+	shards := r.Shards("order")
+	if len(shards) != 16 || shards[0].Table != "order_00" || shards[15].Table != "order_15" {
+		t.Fatalf("Shards() = %v", shards)
+	}
+	s, err := r.Route("order", 21)
+	if err != nil || s.Table != "order_05" {
+		t.Errorf("Route() = %v, %v, want order_05", s, err)
+	}
+	if got := s.Rewrite("SELECT `t1`.`id` FROM `order` AS `t1`", "order"); got != "SELECT `t1`.`id` FROM `order_05` AS `t1`" {
+		t.Errorf("Rewrite() = %s", got)
+	}
+}
+
+func TestShardRewrite(t *testing.T) {
+	s := Shard{Table: "order_05"}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: "SELECT `t1`.`id`, `t1`.`order` FROM `order` AS `t1` WHERE `order` = ?",
+			want:  "SELECT `t1`.`id`, `t1`.`order` FROM `order_05` AS `t1` WHERE `order` = ?",
+		},
+		{
+			query: "INSERT INTO `order` (`order`, `user_id`) VALUES (?, ?)",
+			want:  "INSERT INTO `order_05` (`order`, `user_id`) VALUES (?, ?)",
+		},
+		{
+			query: `UPDATE "order" SET "order" = $1 WHERE "order"."order" = $2`,
+			want:  `UPDATE "order_05" SET "order" = $1 WHERE "order_05"."order" = $2`,
+		},
+		{
+			query: "DELETE FROM `order` WHERE `order` IN (SELECT `order` FROM `order` AS `t2` JOIN `order` AS `t3` ON `t2`.`id` = `t3`.`id`)",
+			want:  "DELETE FROM `order_05` WHERE `order` IN (SELECT `order` FROM `order_05` AS `t2` JOIN `order_05` AS `t3` ON `t2`.`id` = `t3`.`id`)",
+		},
+	}
+	for _, tt := range tests {
+		if got := s.Rewrite(tt.query, "order"); got != tt.want {
+			t.Errorf("Rewrite() = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestShardKeysUpdate(t *testing.T) {
+	keys := ShardKeys{Column: "user_id"}
+	keys.Where(sql.EQ("user_id", 5))
+	if _, err := keys.Resolve(NewHashRouter([]Driver{nil, nil}, 16), nil, "order", false); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	keys.Update("status")
+	keys.Update("user_id")
+	if _, err := keys.Resolve(NewHashRouter([]Driver{nil, nil}, 16), nil, "order", false); err != ErrShardKeyUpdate {
+		t.Errorf("Resolve() error = %v, want %v", err, ErrShardKeyUpdate)
+	}
+}
+
+func TestTxRouter(t *testing.T) {
+	db, other, tx := sqlx.NewDb(nil, "mysql"), sqlx.NewDb(nil, "mysql"), &sqlx.Tx{}
+	r := TxRouter(NewHashRouter([]Driver{db, other}, 4), db, tx)
+	s, err := r.Route("order", 1)
+	if err != nil || s.Driver != Driver(tx) || s.Table != "order_01" {
+		t.Errorf("Route() = %v, %v, want order_01 in tx", s, err)
+	}
+	if _, err := r.Route("order", 2); err != ErrShardTx {
+		t.Errorf("Route() error = %v, want %v", err, ErrShardTx)
+	}
+	keys := ShardKeys{Column: "user_id"}
+	if _, err := keys.Resolve(r, tx, "order", true); err != ErrShardTx {
+		t.Errorf("Resolve() error = %v, want %v", err, ErrShardTx)
+	}
+	if TxRouter(nil, db, tx) != nil {
+		t.Error("TxRouter(nil) != nil")
+	}
+}
@@ -0,0 +1,216 @@
+package esql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrRequired 创建数据时必填字段没有赋值
+	ErrRequired = errors.New("field is required")
+	// ErrImmutable 更新数据时修改了不可变的字段
+	ErrImmutable = errors.New("field is immutable")
+)
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError 写入数据前字段校验失败，Fields 按表字段的顺序包含全部校验失败的字段
+type ValidationError struct {
+	Table  string
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var msgs []string
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Error())
+	}
+	return fmt.Sprintf("esql: validate %s: %s", e.Table, strings.Join(msgs, "; "))
+}
+
+// Is 依次检查字段的错误，支持 errors.Is(err, ErrRequired) 等判断
+func (e *ValidationError) Is(target error) bool {
+	for _, f := range e.Fields {
+		if errors.Is(f, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As 依次检查字段的错误，支持通过 errors.As 获取第一个匹配的错误
+func (e *ValidationError) As(target any) bool {
+	for _, f := range e.Fields {
+		if errors.As(f, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldErrors 收集 Set 时字段的校验错误，同一字段重新赋值时覆盖之前的结果，零值可以直接使用
+type FieldErrors struct {
+	errs map[string]error
+}
+
+// Set 记录字段的校验结果，err 为nil时清除该字段之前的错误
+func (e *FieldErrors) Set(column string, err error) {
+	if err == nil {
+		delete(e.errs, column)
+		return
+	}
+	if e.errs == nil {
+		e.errs = make(map[string]error)
+	}
+	e.errs[column] = err
+}
+
+// Err 按 columns 的顺序汇总校验错误，没有错误时返回nil
+func (e *FieldErrors) Err(table string, columns []string) error {
+	if len(e.errs) == 0 {
+		return nil
+	}
+	verr := &ValidationError{Table: table}
+	for _, column := range columns {
+		if err, ok := e.errs[column]; ok {
+			verr.Fields = append(verr.Fields, &FieldError{Field: column, Err: err})
+		}
+	}
+	return verr
+}
+
+// 以下函数用于生成代码中的字段校验，v 为 Set 传入的值，指针会被解引用，nil 不做校验
+
+// MaxLen 字符串的长度(字符数)不能超过 n
+func MaxLen(v any, n int) error {
+	if s, ok := stringValue(v); ok && utf8.RuneCountInString(s) > n {
+		return fmt.Errorf("length %d exceeds max length %d", utf8.RuneCountInString(s), n)
+	}
+	return nil
+}
+
+// MinLen 字符串的长度(字符数)不能小于 n
+func MinLen(v any, n int) error {
+	if s, ok := stringValue(v); ok && utf8.RuneCountInString(s) < n {
+		return fmt.Errorf("length %d is less than min length %d", utf8.RuneCountInString(s), n)
+	}
+	return nil
+}
+
+// NotEmpty 字符串不能为空
+func NotEmpty(v any) error {
+	if s, ok := stringValue(v); ok && s == "" {
+		return fmt.Errorf("value is empty")
+	}
+	return nil
+}
+
+// Match 字符串需要匹配正则表达式
+func Match(v any, re *regexp.Regexp) error {
+	if s, ok := stringValue(v); ok && !re.MatchString(s) {
+		return fmt.Errorf("value %q does not match %s", s, re)
+	}
+	return nil
+}
+
+// Email 字符串需要是合法的邮箱地址，例如 name@example.com
+func Email(v any) error {
+	if s, ok := stringValue(v); ok {
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s {
+			return fmt.Errorf("value %q is not a valid email address", s)
+		}
+	}
+	return nil
+}
+
+// Range 数值需要在 [min, max] 范围内
+func Range(v any, min, max float64) error {
+	if n, ok := numberValue(v); ok && (n < min || n > max) {
+		return fmt.Errorf("value %v is out of range [%v, %v]", n, min, max)
+	}
+	return nil
+}
+
+// ValidateFunc 使用自定义函数校验，v 不是 T 或 *T 时不做校验
+func ValidateFunc[T any](v any, fn func(T) error) error {
+	switch x := v.(type) {
+	case T:
+		return fn(x)
+	case *T:
+		if x != nil {
+			return fn(*x)
+		}
+	}
+	return nil
+}
+
+// JSONValue 将类型化的JSON字段的值序列化为JSON字符串，nil、[]byte、json.RawMessage 原样返回，
+// nil 指针等序列化为 null 的值写入 NULL
+func JSONValue(v any) (any, error) {
+	switch v.(type) {
+	case nil, []byte, json.RawMessage:
+		return v, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(b) == "null" {
+		return nil, nil
+	}
+	return string(b), nil
+}
+
+func stringValue(v any) (string, bool) {
+	rv, ok := indirect(v)
+	if !ok || rv.Kind() != reflect.String {
+		return "", false
+	}
+	return rv.String(), true
+}
+
+func numberValue(v any) (float64, bool) {
+	rv, ok := indirect(v)
+	if !ok {
+		return 0, false
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func indirect(v any) (reflect.Value, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return rv, false
+		}
+		rv = rv.Elem()
+	}
+	return rv, rv.IsValid()
+}
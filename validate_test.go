@@ -0,0 +1,85 @@
+package esql
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestFieldErrors(t *testing.T) {
+	var errs FieldErrors
+	if err := errs.Err("user", []string{"name"}); err != nil {
+		t.Fatalf("Err = %v, want nil", err)
+	}
+
+	errs.Set("name", NotEmpty(""))
+	errs.Set("age", Range(200, 0, 150))
+	errs.Set("email", Email("foo"))
+	errs.Set("email", Email("foo@example.com"))
+
+	err := errs.Err("user", []string{"id", "age", "name", "email"})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Err = %v, want *ValidationError", err)
+	}
+	if verr.Table != "user" || len(verr.Fields) != 2 {
+		t.Fatalf("ValidationError = %v", verr)
+	}
+	if verr.Fields[0].Field != "age" || verr.Fields[1].Field != "name" {
+		t.Errorf("Fields = %v, %v, want age, name", verr.Fields[0], verr.Fields[1])
+	}
+
+	errs.Set("email", ErrRequired)
+	if err := errs.Err("user", []string{"email"}); !errors.Is(err, ErrRequired) {
+		t.Errorf("Err = %v, want ErrRequired", err)
+	}
+}
+
+func TestValidators(t *testing.T) {
+	name := "张三"
+	var nilName *string
+	tests := []struct {
+		err  error
+		fail bool
+	}{
+		{MaxLen("abc", 3), false},
+		{MaxLen("abcd", 3), true},
+		{MaxLen(&name, 2), false},
+		{MaxLen(nilName, 0), false},
+		{MinLen("ab", 3), true},
+		{NotEmpty(""), true},
+		{NotEmpty(&name), false},
+		{Match("a1", regexp.MustCompile(`^[a-z]\d$`)), false},
+		{Match("1a", regexp.MustCompile(`^[a-z]\d$`)), true},
+		{Email("foo@example.com"), false},
+		{Email("Foo <foo@example.com>"), true},
+		{Range(int8(5), 1, 10), false},
+		{Range(uint(11), 1, 10), true},
+		{Range(0.5, 1, 10), true},
+		{Range(nil, 1, 10), false},
+	}
+	for i, tt := range tests {
+		if (tt.err != nil) != tt.fail {
+			t.Errorf("%d: err = %v, want fail %v", i, tt.err, tt.fail)
+		}
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	var p *struct{ A int }
+	tests := []struct {
+		v    any
+		want any
+	}{
+		{nil, nil},
+		{p, nil},
+		{[]string{"a"}, `["a"]`},
+		{struct{ A int }{1}, `{"A":1}`},
+	}
+	for _, tt := range tests {
+		got, err := JSONValue(tt.v)
+		if err != nil || got != tt.want {
+			t.Errorf("JSONValue(%v) = %v, %v, want %v", tt.v, got, err, tt.want)
+		}
+	}
+}